@@ -2,6 +2,9 @@ package db
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -20,10 +23,303 @@ type Schedule struct {
 	Subgroup   string
 }
 
+var lessonTimeRegex = regexp.MustCompile(`(\d{1,2})[:.](\d{2})`)
+
+const defaultLessonDuration = 80 * time.Minute
+
+func (s Schedule) Period(year int, loc *time.Location) (time.Time, time.Time, error) {
+	date, err := time.ParseInLocation("02.01.2006", fmt.Sprintf("%s.%d", s.LessonDate, year), loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse lesson date %q: %w", s.LessonDate, err)
+	}
+
+	matches := lessonTimeRegex.FindAllStringSubmatch(s.LessonTime, 2)
+	if len(matches) == 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse lesson time %q", s.LessonTime)
+	}
+
+	clock := func(m []string) time.Time {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+	}
+
+	start := clock(matches[0])
+	end := start.Add(defaultLessonDuration)
+	if len(matches) > 1 {
+		end = clock(matches[1])
+	}
+
+	return start, end, nil
+}
+
+// TermStartYear returns the calendar year in which the academic year that
+// contains now began. The academic year starts in August.
+func TermStartYear(now time.Time) int {
+	if now.Month() < time.August {
+		return now.Year() - 1
+	}
+	return now.Year()
+}
+
+// LessonYear resolves the year of a "dd.mm" lesson date within the academic
+// year that contains now.
+func LessonYear(lessonDate string, now time.Time) int {
+	date, err := time.Parse("02.01", lessonDate)
+	if err != nil {
+		return now.Year()
+	}
+
+	year := TermStartYear(now)
+	if date.Month() < time.August {
+		year++
+	}
+	return year
+}
+
+var weekdays = map[string]time.Weekday{
+	"понедельник": time.Monday,
+	"вторник":     time.Tuesday,
+	"среда":       time.Wednesday,
+	"четверг":     time.Thursday,
+	"пятница":     time.Friday,
+	"суббота":     time.Saturday,
+	"воскресенье": time.Sunday,
+	"панядзелак":  time.Monday,
+	"аўторак":     time.Tuesday,
+	"серада":      time.Wednesday,
+	"чацвер":      time.Thursday,
+	"пятніца":     time.Friday,
+	"субота":      time.Saturday,
+	"нядзеля":     time.Sunday,
+	"monday":      time.Monday,
+	"tuesday":     time.Tuesday,
+	"wednesday":   time.Wednesday,
+	"thursday":    time.Thursday,
+	"friday":      time.Friday,
+	"saturday":    time.Saturday,
+	"sunday":      time.Sunday,
+}
+
+func ParseWeekday(name string) (time.Weekday, bool) {
+	weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+	return weekday, ok
+}
+
+// ShortTeacherName turns "Surname Name Patronymic" into "Surname N. P." and
+// leaves other names as they are.
+func ShortTeacherName(fullName string) string {
+	parts := strings.Fields(fullName)
+	if len(parts) < 3 {
+		return fullName
+	}
+	return fmt.Sprintf("%s %c. %c.", parts[0], []rune(parts[1])[0], []rune(parts[2])[0])
+}
+
+func (s Schedule) AppliesTo(subgroup string) bool {
+	return subgroup == "" || s.Subgroup == "" || s.Subgroup == subgroup
+}
+
 type Users struct {
-	TelegramID int64  `pg:",pk"`
-	GroupName  string `pg:",notnull"`
-	IsBanned   bool   `pg:",use_zero,default:false"`
+	TelegramID      int64  `pg:",pk"`
+	GroupName       string `pg:",notnull,use_zero"`
+	IsBanned        bool   `pg:",use_zero,default:false"`
+	Subgroups       map[string]string
+	Groups          []string `pg:",array"`
+	FeedToken       string   `pg:",unique"`
+	WeekView        string
+	ViewPrefs       ViewPrefs
+	Language        string
+	TermsAcceptedAt time.Time
+	TermsVersion    int
+	BannedUntil     time.Time
+	BanReason       string
+	Role            string
+	RoleGroup       string
+	TeacherName     string
+	LastSeenAt      time.Time
+	BlockedAt       time.Time
+}
+
+// SubgroupOf returns the subgroup chosen for one of the saved groups.
+func (u Users) SubgroupOf(group string) string {
+	return u.Subgroups[group]
+}
+
+func (u Users) IsBannedAt(now time.Time) bool {
+	return u.IsBanned && (u.BannedUntil.IsZero() || now.Before(u.BannedUntil))
+}
+
+const (
+	ActionBan     = "ban"
+	ActionTempBan = "tempban"
+	ActionUnban   = "unban"
+)
+
+const (
+	RoleStudent = "student"
+	RoleHeadman = "headman"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+const (
+	RequestPending  = "pending"
+	RequestApproved = "approved"
+	RequestRejected = "rejected"
+)
+
+type RoleRequest struct {
+	ID          int64
+	UserID      int64  `pg:",notnull"`
+	Role        string `pg:",notnull"`
+	GroupName   string
+	TeacherName string
+	Status      string `pg:",notnull"`
+	ReviewedBy  int64
+	CreatedAt   time.Time `pg:",notnull"`
+	ReviewedAt  time.Time
+}
+
+const (
+	BroadcastDraft     = "draft"
+	BroadcastSending   = "sending"
+	BroadcastDone      = "done"
+	BroadcastCancelled = "cancelled"
+)
+
+type Broadcast struct {
+	ID         int64
+	AdminID    int64    `pg:",notnull"`
+	Text       string   `pg:",notnull"`
+	Groups     []string `pg:",array"`
+	Year       string
+	Spec       string
+	ActiveOnly bool      `pg:",use_zero"`
+	Status     string    `pg:",notnull"`
+	CreatedAt  time.Time `pg:",notnull"`
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliveryBlocked = "blocked"
+)
+
+type BroadcastDelivery struct {
+	BroadcastID int64  `pg:",pk"`
+	UserID      int64  `pg:",pk"`
+	Status      string `pg:",notnull"`
+	Error       string
+	SentAt      time.Time
+}
+
+const (
+	EventCommand  = "command"
+	EventCallback = "callback"
+	EventInline   = "inline"
+	EventView     = "view"
+	EventExport   = "export"
+	EventError    = "error"
+	EventLatency  = "latency"
+)
+
+type Event struct {
+	ID        int64
+	UserID    int64
+	Kind      string `pg:",notnull"`
+	Name      string `pg:",notnull"`
+	GroupName string
+	Duration  time.Duration
+	CreatedAt time.Time `pg:",notnull"`
+}
+
+const (
+	ReportDraft    = "draft"
+	ReportOpen     = "open"
+	ReportAnswered = "answered"
+	ReportClosed   = "closed"
+)
+
+type Report struct {
+	ID        int64
+	UserID    int64  `pg:",notnull"`
+	GroupName string `pg:",notnull"`
+	View      string `pg:",notnull"`
+	ViewDate  string
+	Comment   string
+	Status    string `pg:",notnull"`
+	Forwards  []ReportForward
+	CreatedAt time.Time `pg:",notnull"`
+	ClosedAt  time.Time
+}
+
+// ReportForward is a copy of a report sent to an admin chat. Replies to it
+// are answers to the report.
+type ReportForward struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int   `json:"message_id"`
+}
+
+type ModerationAction struct {
+	ID        int64
+	AdminID   int64  `pg:",notnull"`
+	UserID    int64  `pg:",notnull"`
+	Action    string `pg:",notnull"`
+	Reason    string
+	Until     time.Time
+	CreatedAt time.Time `pg:",notnull"`
+}
+
+const (
+	LayoutCompact  = "compact"
+	LayoutDetailed = "detailed"
+	TeachersFull   = "full"
+	TeachersShort  = "short"
+	TimeStart      = "start"
+	TimeRange      = "range"
+)
+
+type ViewPrefs struct {
+	Layout     string `json:"layout,omitempty"`
+	Teachers   string `json:"teachers,omitempty"`
+	HideRooms  bool   `json:"hide_rooms,omitempty"`
+	Emoji      bool   `json:"emoji,omitempty"`
+	TimeFormat string `json:"time_format,omitempty"`
+}
+
+type ChatBinding struct {
+	ChatID          int64     `pg:",pk"`
+	GroupName       string    `pg:",notnull"`
+	BoundBy         int64     `pg:",notnull"`
+	BoundAt         time.Time `pg:",notnull"`
+	AutoPost        bool      `pg:",notnull,default:true"`
+	PinnedMessageID int
+	PinnedDate      string
+	PinnedHash      string
+	Language        string
+}
+
+type Channel struct {
+	ChatID         int64 `pg:",pk"`
+	Title          string
+	Groups         []string `pg:",array"`
+	Year           string
+	Spec           string
+	AddedBy        int64     `pg:",notnull"`
+	AddedAt        time.Time `pg:",notnull"`
+	LastWeeklyPost string
+}
+
+type ChannelGroupState struct {
+	ChannelID int64  `pg:",pk"`
+	GroupName string `pg:",pk"`
+	WeekStart string `pg:",pk"`
+	Hash      string `pg:",notnull"`
+	Posted    bool   `pg:",notnull,default:false"`
 }
 
 type Metadata struct {
@@ -31,6 +327,60 @@ type Metadata struct {
 	LastUpdate time.Time `pg:",notnull"`
 }
 
+var migrations = []string{
+	// Saved groups are backfilled from group_name only when the column is
+	// first added, so later restarts leave users' lists alone.
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'groups') THEN
+			ALTER TABLE users ADD COLUMN groups text[];
+			UPDATE users SET groups = ARRAY[group_name] WHERE group_name <> '';
+		END IF;
+	END $$`,
+	`UPDATE users SET groups = array_remove(groups, '') WHERE '' = ANY(groups)`,
+	`ALTER TABLE chat_bindings ADD COLUMN IF NOT EXISTS auto_post boolean NOT NULL DEFAULT true`,
+	`ALTER TABLE chat_bindings ADD COLUMN IF NOT EXISTS pinned_message_id bigint`,
+	`ALTER TABLE chat_bindings ADD COLUMN IF NOT EXISTS pinned_date text`,
+	`ALTER TABLE chat_bindings ADD COLUMN IF NOT EXISTS pinned_hash text`,
+	// Existing chats take the language of the user who bound them.
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'chat_bindings' AND column_name = 'language') THEN
+			ALTER TABLE chat_bindings ADD COLUMN language text;
+			UPDATE chat_bindings SET language = users.language FROM users WHERE users.telegram_id = chat_bindings.bound_by;
+		END IF;
+	END $$`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS feed_token text UNIQUE`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS week_view text`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS view_prefs jsonb`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS language text`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS terms_accepted_at timestamptz`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS terms_version integer`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_until timestamptz`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS ban_reason text`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS role text`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS role_group text`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS teacher_name text`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at timestamptz`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS blocked_at timestamptz`,
+	`CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at)`,
+	`ALTER TABLE reports ADD COLUMN IF NOT EXISTS forwards jsonb`,
+	`ALTER TABLE channel_group_states ADD COLUMN IF NOT EXISTS posted boolean NOT NULL DEFAULT false`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS subgroups jsonb`,
+	// The single per-user subgroup is moved into subgroups once and the old
+	// column dropped.
+	`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'subgroup') THEN
+			UPDATE users SET subgroups = jsonb_build_object(group_name, subgroup) WHERE COALESCE(subgroup, '') <> '' AND group_name <> '';
+			ALTER TABLE users DROP COLUMN subgroup;
+		END IF;
+	END $$`,
+}
+
 func InitDB(databaseURL string) (*pg.DB, error) {
 	opt, err := pg.ParseURL(databaseURL)
 	if err != nil {
@@ -50,6 +400,15 @@ func createSchema(db *pg.DB) error {
 		(*Schedule)(nil),
 		(*Users)(nil),
 		(*Metadata)(nil),
+		(*ChatBinding)(nil),
+		(*Channel)(nil),
+		(*ChannelGroupState)(nil),
+		(*ModerationAction)(nil),
+		(*RoleRequest)(nil),
+		(*Broadcast)(nil),
+		(*BroadcastDelivery)(nil),
+		(*Event)(nil),
+		(*Report)(nil),
 	}
 
 	for _, model := range models {
@@ -58,6 +417,12 @@ func createSchema(db *pg.DB) error {
 		}
 	}
 
+	for _, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to apply migration %q: %w", migration, err)
+		}
+	}
+
 	var count int
 	_, err := db.QueryOne(pg.Scan(&count), `SELECT COUNT(*) FROM metadata`)
 	if err != nil {
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLessonYear(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		lessonDate string
+		now        time.Time
+		want       int
+	}{
+		{name: "autumn lesson in january", lessonDate: "15.09", now: date(2026, time.January, 10), want: 2025},
+		{name: "spring lesson in january", lessonDate: "15.03", now: date(2026, time.January, 10), want: 2026},
+		{name: "autumn lesson in march", lessonDate: "15.09", now: date(2026, time.March, 10), want: 2025},
+		{name: "spring lesson in march", lessonDate: "15.03", now: date(2026, time.March, 10), want: 2026},
+		{name: "autumn lesson in july", lessonDate: "01.12", now: date(2026, time.July, 31), want: 2025},
+		{name: "spring lesson in july", lessonDate: "01.07", now: date(2026, time.July, 31), want: 2026},
+		{name: "autumn lesson in august", lessonDate: "15.09", now: date(2026, time.August, 1), want: 2026},
+		{name: "spring lesson in august", lessonDate: "15.03", now: date(2026, time.August, 1), want: 2027},
+		{name: "autumn lesson in december", lessonDate: "15.09", now: date(2026, time.December, 20), want: 2026},
+		{name: "january lesson in december", lessonDate: "15.01", now: date(2026, time.December, 20), want: 2027},
+		{name: "invalid date", lessonDate: "32.13", now: date(2026, time.March, 10), want: 2026},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LessonYear(tt.lessonDate, tt.now); got != tt.want {
+				t.Errorf("LessonYear(%q, %v) = %d, want %d", tt.lessonDate, tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortTeacherName(t *testing.T) {
+	tests := map[string]string{
+		"Иванов Иван Иванович":   "Иванов И. И.",
+		" Петров  Пётр Петрович": "Петров П. П.",
+		"Сидорова А.":            "Сидорова А.",
+		"":                       "",
+	}
+	for name, want := range tests {
+		if got := ShortTeacherName(name); got != want {
+			t.Errorf("ShortTeacherName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
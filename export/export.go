@@ -0,0 +1,152 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+)
+
+const SchemaVersion = 1
+
+// Record is the stable export schema: fields are only ever added, never renamed or removed.
+// Dates are YYYY-MM-DD and times HH:MM in the location of the now passed to Query;
+// empty values are exported as "".
+type Record struct {
+	Group    string `json:"group"`
+	Date     string `json:"date"`
+	Weekday  string `json:"weekday"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Subject  string `json:"subject"`
+	Room     string `json:"room"`
+	Teacher  string `json:"teacher"`
+	Subgroup string `json:"subgroup"`
+}
+
+var Columns = []string{"group", "date", "weekday", "start", "end", "subject", "room", "teacher", "subgroup"}
+
+type Filter struct {
+	Group   string
+	Teacher string
+	Room    string
+	From    time.Time
+	To      time.Time
+}
+
+type document struct {
+	SchemaVersion int       `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	Records       []Record  `json:"records"`
+}
+
+func Query(dbConn *pg.DB, filter Filter, now time.Time) ([]Record, error) {
+	if filter.Group == "" && filter.Teacher == "" && filter.Room == "" {
+		return nil, fmt.Errorf("group, teacher or room filter is required")
+	}
+
+	var schedules []db.Schedule
+	query := dbConn.Model(&schedules)
+	if filter.Group != "" {
+		query = query.Where("group_name = ?", filter.Group)
+	}
+	if filter.Teacher != "" {
+		query = query.Where("strpos(lower(teacher), lower(?)) > 0", filter.Teacher)
+	}
+	if filter.Room != "" {
+		query = query.Where("strpos(lower(location), lower(?)) > 0", filter.Room)
+	}
+	if err := query.Select(); err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	records := make([]Record, 0, len(schedules))
+	for _, schedule := range schedules {
+		start, end, err := schedule.Period(db.LessonYear(schedule.LessonDate, now), now.Location())
+		if err != nil {
+			continue
+		}
+
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		if !filter.From.IsZero() && day.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && day.After(filter.To) {
+			continue
+		}
+
+		records = append(records, Record{
+			Group:    schedule.GroupName,
+			Date:     start.Format("2006-01-02"),
+			Weekday:  schedule.DayOfWeek,
+			Start:    start.Format("15:04"),
+			End:      end.Format("15:04"),
+			Subject:  strings.TrimSpace(schedule.LessonName),
+			Room:     schedule.Location,
+			Teacher:  schedule.Teacher,
+			Subgroup: schedule.Subgroup,
+		})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.Subgroup < b.Subgroup
+	})
+
+	return records, nil
+}
+
+func WriteCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Columns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, r := range records {
+		row := []string{r.Group, r.Date, r.Weekday, r.Start, r.End, r.Subject, r.Room, r.Teacher, r.Subgroup}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteJSON(w io.Writer, records []Record, now time.Time) error {
+	if records == nil {
+		records = []Record{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document{SchemaVersion: SchemaVersion, GeneratedAt: now.UTC(), Records: records}); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+func Write(w io.Writer, format string, records []Record, now time.Time) error {
+	switch format {
+	case "csv":
+		return WriteCSV(w, records)
+	case "json":
+		return WriteJSON(w, records, now)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/Ah3ron/schedule-bot/ical"
+	"github.com/go-pg/pg/v10"
+)
+
+const pathPrefix = "/ical/"
+
+// Labels localizes a feed for the language of its owner.
+type Labels struct {
+	Calendar string // format with the group name
+	Event    ical.Labels
+}
+
+func Start(addr string, dbConn *pg.DB, labels func(lang string) Labels) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathPrefix, func(w http.ResponseWriter, r *http.Request) {
+		serveFeed(w, r, dbConn, labels)
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Printf("Serving calendar feeds on %s\n", addr)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Printf("Feed server stopped: %v\n", err)
+	}
+}
+
+func URL(publicURL, token string) string {
+	return strings.TrimRight(publicURL, "/") + pathPrefix + token + ".ics"
+}
+
+func serveFeed(w http.ResponseWriter, r *http.Request, dbConn *pg.DB, labels func(lang string) Labels) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, pathPrefix), ".ics")
+	if !ok || token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var user db.Users
+	err := dbConn.Model(&user).Where("feed_token = ?", token).Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to fetch feed owner: %v\n", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	// Banned users' feeds stop working, like the exports in the bot.
+	if user.IsBannedAt(time.Now()) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var lastUpdate time.Time
+	err = dbConn.Model((*db.Metadata)(nil)).ColumnExpr("MAX(last_update)").Select(&lastUpdate)
+	if err != nil {
+		fmt.Printf("Failed to fetch last update: %v\n", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	lastUpdate = lastUpdate.UTC().Truncate(time.Second)
+
+	var schedules []db.Schedule
+	err = dbConn.Model(&schedules).Where("group_name = ?", user.GroupName).Select()
+	if err != nil {
+		fmt.Printf("Failed to fetch schedule for feed: %v\n", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	subgroup := user.SubgroupOf(user.GroupName)
+	filtered := schedules[:0]
+	for _, schedule := range schedules {
+		if schedule.AppliesTo(subgroup) {
+			filtered = append(filtered, schedule)
+		}
+	}
+
+	userLabels := labels(user.Language)
+	name := fmt.Sprintf(userLabels.Calendar, user.GroupName)
+	if subgroup != "" {
+		name += " (" + subgroup + ")"
+	}
+
+	calendar := ical.Build(name, filtered, time.Now(), lastUpdate, userLabels.Event)
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag(user, lastUpdate))
+	http.ServeContent(w, r, "", lastUpdate, bytes.NewReader(calendar))
+}
+
+func etag(user db.Users, lastUpdate time.Time) string {
+	key := fmt.Sprintf("%s|%s|%s|%d", user.GroupName, user.SubgroupOf(user.GroupName), user.Language, lastUpdate.Unix())
+	sum := sha256.Sum256([]byte(key))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -5,8 +5,10 @@ go 1.23
 toolchain go1.23.1
 
 require (
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/go-pg/pg/v10 v10.13.0
 	github.com/gocolly/colly v1.2.0
+	golang.org/x/image v0.20.0
 	gopkg.in/telebot.v3 v3.3.8
 )
 
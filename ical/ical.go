@@ -0,0 +1,158 @@
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+const (
+	TimeZone    = "Europe/Minsk"
+	maxLineSize = 75
+)
+
+const vtimezone = "BEGIN:VTIMEZONE\r\n" +
+	"TZID:" + TimeZone + "\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"TZOFFSETFROM:+0300\r\n" +
+	"TZOFFSETTO:+0300\r\n" +
+	"TZNAME:+03\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n"
+
+func Location() *time.Location {
+	loc, err := time.LoadLocation(TimeZone)
+	if err != nil {
+		return time.FixedZone("+03", 3*60*60)
+	}
+	return loc
+}
+
+// Labels names the fields of event descriptions in the reader's language.
+type Labels struct {
+	Teacher  string
+	Subgroup string
+	Group    string
+}
+
+type event struct {
+	uid      string
+	start    time.Time
+	end      time.Time
+	schedule db.Schedule
+}
+
+func Build(name string, schedules []db.Schedule, now, stamp time.Time, labels Labels) []byte {
+	loc := Location()
+
+	seen := make(map[string]struct{}, len(schedules))
+	var events []event
+	for _, schedule := range schedules {
+		start, end, err := schedule.Period(db.LessonYear(schedule.LessonDate, now), loc)
+		if err != nil {
+			continue
+		}
+
+		uid := eventUID(schedule, start)
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+
+		events = append(events, event{uid: uid, start: start, end: end, schedule: schedule})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].start.Before(events[j].start)
+	})
+
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//schedule-bot//RU")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(name))
+	writeLine(&b, "X-WR-TIMEZONE:"+TimeZone)
+	b.WriteString(vtimezone)
+
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+e.uid)
+		writeLine(&b, "DTSTAMP:"+dtstamp)
+		writeLine(&b, "DTSTART;TZID="+TimeZone+":"+e.start.Format("20060102T150405"))
+		writeLine(&b, "DTEND;TZID="+TimeZone+":"+e.end.Format("20060102T150405"))
+		writeLine(&b, "SUMMARY:"+escapeText(summary(e.schedule)))
+		if e.schedule.Location != "" {
+			writeLine(&b, "LOCATION:"+escapeText(e.schedule.Location))
+		}
+		if description := description(e.schedule, labels); description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(description))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func eventUID(schedule db.Schedule, start time.Time) string {
+	key := strings.Join([]string{
+		schedule.GroupName,
+		start.Format("20060102T1504"),
+		schedule.LessonName,
+		schedule.Subgroup,
+	}, "|")
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:]) + "@schedule-bot"
+}
+
+func summary(schedule db.Schedule) string {
+	if schedule.Subgroup != "" {
+		return fmt.Sprintf("%s (%s)", schedule.LessonName, schedule.Subgroup)
+	}
+	return schedule.LessonName
+}
+
+func description(schedule db.Schedule, labels Labels) string {
+	var lines []string
+	if schedule.Teacher != "" {
+		lines = append(lines, labels.Teacher+": "+schedule.Teacher)
+	}
+	if schedule.Subgroup != "" {
+		lines = append(lines, labels.Subgroup+": "+schedule.Subgroup)
+	}
+	lines = append(lines, labels.Group+": "+schedule.GroupName)
+	return strings.Join(lines, "\n")
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+func writeLine(b *strings.Builder, line string) {
+	size := 0
+	for _, r := range line {
+		runeSize := len(string(r))
+		if size+runeSize > maxLineSize {
+			b.WriteString("\r\n ")
+			size = 1
+		}
+		b.WriteRune(r)
+		size += runeSize
+	}
+	b.WriteString("\r\n")
+}
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/Ah3ron/schedule-bot/export"
+	"github.com/Ah3ron/schedule-bot/feed"
 	"github.com/Ah3ron/schedule-bot/scraper"
 	"github.com/Ah3ron/schedule-bot/telegram_bot"
+	"github.com/go-pg/pg/v10"
 )
 
 func main() {
@@ -21,8 +30,105 @@ func main() {
 	}
 	defer dbConn.Close()
 
-	go scraper.Start(dbConn)
-	go telegram_bot.Start(os.Getenv("TELEGRAM_TOKEN"), dbConn)
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(dbConn, os.Args[2:]); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	}
+
+	updates := make(chan struct{}, 1)
+	notifyUpdate := func() {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+
+	adminIDs, err := parseIDs(os.Getenv("ADMIN_IDS"))
+	if err != nil {
+		log.Fatalf("Invalid ADMIN_IDS: %v", err)
+	}
+
+	var adminChatID int64
+	if value := os.Getenv("ADMIN_CHAT_ID"); value != "" {
+		adminChatID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid ADMIN_CHAT_ID: %v", err)
+		}
+	}
+
+	httpAddr := os.Getenv("HTTP_ADDR")
+	if httpAddr == "" {
+		httpAddr = ":8080"
+	}
+
+	go scraper.Start(dbConn, notifyUpdate)
+	go feed.Start(httpAddr, dbConn, telegram_bot.FeedLabels)
+	go telegram_bot.Start(telegram_bot.Config{
+		Token:       os.Getenv("TELEGRAM_TOKEN"),
+		FeedURL:     os.Getenv("PUBLIC_URL"),
+		AdminIDs:    adminIDs,
+		AdminChatID: adminChatID,
+		BanMode:     os.Getenv("BAN_MODE"),
+	}, dbConn, updates)
 
 	select {}
 }
+
+func parseIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q: %w", field, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func runExport(dbConn *pg.DB, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "csv", "output format: csv or json")
+	group := flags.String("group", "", "group name, e.g. 22ИТ-1")
+	teacher := flags.String("teacher", "", "teacher name or its part")
+	room := flags.String("room", "", "room or its part")
+	from := flags.String("from", "", "first date, DD.MM.YYYY")
+	to := flags.String("to", "", "last date, DD.MM.YYYY")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Parse(args)
+
+	filter := export.Filter{Group: *group, Teacher: *teacher, Room: *room}
+	for _, date := range []struct {
+		value  string
+		target *time.Time
+	}{{*from, &filter.From}, {*to, &filter.To}} {
+		if date.value == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("02.01.2006", date.value, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %w", date.value, err)
+		}
+		*date.target = t
+	}
+
+	now := time.Now()
+	records, err := export.Query(dbConn, filter, now)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return export.Write(w, *format, records, now)
+}
@@ -0,0 +1,182 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const (
+	pdfFont       = "go"
+	pdfMargin     = 10.0
+	pdfLineHeight = 5.0
+	pdfCellPad    = 1.5
+)
+
+var pdfColumnWidths = []float64{34, 24, 90, 26, 73, 30}
+
+type pdfLesson struct {
+	date     time.Time
+	schedule db.Schedule
+}
+
+// groupPDFWeeks dates the lessons within the academic year of now and groups
+// them by the Monday of their week, returning the Mondays in order.
+func groupPDFWeeks(schedules []db.Schedule, now time.Time) (map[time.Time][]pdfLesson, []time.Time) {
+	weeks := make(map[time.Time][]pdfLesson)
+	for _, schedule := range schedules {
+		date, err := time.Parse("02.01.2006", fmt.Sprintf("%s.%d", schedule.LessonDate, db.LessonYear(schedule.LessonDate, now)))
+		if err != nil {
+			continue
+		}
+		monday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		weeks[monday] = append(weeks[monday], pdfLesson{date: date, schedule: schedule})
+	}
+
+	mondays := make([]time.Time, 0, len(weeks))
+	for monday := range weeks {
+		mondays = append(mondays, monday)
+	}
+	sort.Slice(mondays, func(i, j int) bool {
+		return mondays[i].Before(mondays[j])
+	})
+	return weeks, mondays
+}
+
+func TimetablePDF(title string, schedules []db.Schedule, now, updated time.Time, labels Labels) ([]byte, error) {
+	weeks, mondays := groupPDFWeeks(schedules, now)
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", gobold.TTF)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin+5)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("schedule-bot", true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin - 3)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(0, 4, fmt.Sprintf(labels.UpdatedAt, updated.Format("02.01.2006 15:04")), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 4, fmt.Sprintf(labels.Page, pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont(pdfFont, "B", 16)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+
+	if len(mondays) == 0 {
+		pdf.SetFont(pdfFont, "", 11)
+		pdf.CellFormat(0, 8, labels.NoLessons, "", 1, "L", false, 0, "")
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	for _, monday := range mondays {
+		lessons := weeks[monday]
+		sort.SliceStable(lessons, func(i, j int) bool {
+			if !lessons[i].date.Equal(lessons[j].date) {
+				return lessons[i].date.Before(lessons[j].date)
+			}
+			return slotMinutes(lessons[i].schedule.LessonTime) < slotMinutes(lessons[j].schedule.LessonTime)
+		})
+
+		if pdf.GetY()+30 > pageHeight-bottomMargin {
+			pdf.AddPage()
+		}
+
+		pdf.Ln(3)
+		pdf.SetFont(pdfFont, "B", 12)
+		pdf.SetTextColor(0, 0, 0)
+		sunday := monday.AddDate(0, 0, 6)
+		pdf.CellFormat(0, 7, fmt.Sprintf(labels.Week, monday.Format("02.01.2006"), sunday.Format("02.01.2006")), "", 1, "L", false, 0, "")
+
+		pdfTableHeader(pdf, labels)
+
+		var previousDay time.Time
+		for _, lesson := range lessons {
+			day := ""
+			if !lesson.date.Equal(previousDay) {
+				day = lesson.schedule.DayOfWeek + "\n" + lesson.date.Format("02.01")
+				previousDay = lesson.date
+			}
+
+			row := []string{
+				day,
+				strings.TrimSpace(lesson.schedule.LessonTime),
+				lesson.schedule.LessonName,
+				lesson.schedule.Location,
+				lesson.schedule.Teacher,
+				lesson.schedule.Subgroup,
+			}
+			if pdf.GetY()+pdfRowHeight(pdf, row) > pageHeight-bottomMargin {
+				pdf.AddPage()
+				pdfTableHeader(pdf, labels)
+			}
+			pdfTableRow(pdf, row)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func pdfTableHeader(pdf *fpdf.Fpdf, labels Labels) {
+	pdf.SetFont(pdfFont, "B", 9)
+	pdf.SetFillColor(0x2f, 0x3e, 0x56)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetDrawColor(0xa0, 0xa8, 0xb4)
+	titles := []string{labels.Day, labels.Time, labels.Subject, labels.Room, labels.Teacher, labels.Subgroup}
+	for i, title := range titles {
+		pdf.CellFormat(pdfColumnWidths[i], 6, title, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func pdfRowHeight(pdf *fpdf.Fpdf, row []string) float64 {
+	pdf.SetFont(pdfFont, "", 9)
+	lines := 1
+	for i, text := range row {
+		n := 0
+		for _, part := range strings.Split(text, "\n") {
+			n += len(pdf.SplitText(part, pdfColumnWidths[i]-2*pdfCellPad))
+		}
+		if n > lines {
+			lines = n
+		}
+	}
+	return float64(lines)*pdfLineHeight + 2*pdfCellPad
+}
+
+func pdfTableRow(pdf *fpdf.Fpdf, row []string) {
+	height := pdfRowHeight(pdf, row)
+	x, y := pdf.GetXY()
+
+	pdf.SetTextColor(0, 0, 0)
+	for i, text := range row {
+		width := pdfColumnWidths[i]
+		style := ""
+		if i == 0 {
+			style = "B"
+		}
+		pdf.SetFont(pdfFont, style, 9)
+
+		pdf.Rect(x, y, width, height, "D")
+		pdf.SetXY(x+pdfCellPad, y+pdfCellPad)
+		pdf.MultiCell(width-2*pdfCellPad, pdfLineHeight, text, "", "L", false)
+		x += width
+	}
+
+	pdf.SetXY(pdfMargin, y+height)
+}
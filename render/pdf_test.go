@@ -0,0 +1,37 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+func TestGroupPDFWeeks(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	schedules := []db.Schedule{
+		{LessonDate: "16.03", LessonName: "Spring"},
+		{LessonDate: "15.09", LessonName: "Autumn"},
+		{LessonDate: "12.01", LessonName: "Winter"},
+		{LessonDate: "17.09", LessonName: "Autumn again"},
+	}
+
+	weeks, mondays := groupPDFWeeks(schedules, now)
+
+	want := []time.Time{
+		time.Date(2025, time.September, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.January, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC),
+	}
+	if len(mondays) != len(want) {
+		t.Fatalf("got %d weeks %v, want %v", len(mondays), mondays, want)
+	}
+	for i := range want {
+		if !mondays[i].Equal(want[i]) {
+			t.Errorf("week %d starts %v, want %v", i, mondays[i], want[i])
+		}
+	}
+	if got := len(weeks[want[0]]); got != 2 {
+		t.Errorf("first week has %d lessons, want 2", got)
+	}
+}
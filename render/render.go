@@ -0,0 +1,406 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	dayColumnWidth = 150
+	slotWidth      = 240
+	headerHeight   = 56
+	slotHeaderSize = 40
+	padding        = 8
+	blockGap       = 6
+	legendHeight   = 44
+)
+
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	headerColor     = color.RGBA{0x2f, 0x3e, 0x56, 0xff}
+	gridColor       = color.RGBA{0xd5, 0xdb, 0xe3, 0xff}
+	dayColor        = color.RGBA{0xf1, 0xf4, 0xf8, 0xff}
+	textColor       = color.RGBA{0x1f, 0x25, 0x2d, 0xff}
+	mutedColor      = color.RGBA{0x5c, 0x66, 0x73, 0xff}
+	lightTextColor  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+type LessonType int
+
+const (
+	LessonOther LessonType = iota
+	LessonLecture
+	LessonPractice
+	LessonLab
+	LessonExam
+)
+
+type lessonStyle struct {
+	kind   LessonType
+	fill   color.RGBA
+	stripe color.RGBA
+	marks  []string
+}
+
+var lessonStyles = []lessonStyle{
+	{LessonLecture, color.RGBA{0xe3, 0xee, 0xfd, 0xff}, color.RGBA{0x3b, 0x7d, 0xdd, 0xff}, []string{"лк", "лек", "лекц", "лекция"}},
+	{LessonPractice, color.RGBA{0xe4, 0xf6, 0xe7, 0xff}, color.RGBA{0x3a, 0xa6, 0x55, 0xff}, []string{"пр", "пз", "практ", "сем", "семинар"}},
+	{LessonLab, color.RGBA{0xfd, 0xf0, 0xdd, 0xff}, color.RGBA{0xe0, 0x8a, 0x1e, 0xff}, []string{"лб", "лр", "лаб"}},
+	{LessonExam, color.RGBA{0xfc, 0xe4, 0xe4, 0xff}, color.RGBA{0xd6, 0x45, 0x45, 0xff}, []string{"экз", "экзамен", "зач", "зачет", "зачёт", "конс", "консультация"}},
+	{LessonOther, color.RGBA{0xee, 0xee, 0xf0, 0xff}, color.RGBA{0x8a, 0x8f, 0x98, 0xff}, nil},
+}
+
+// Labels holds the text drawn on rendered timetables, in the reader's
+// language.
+type Labels struct {
+	Day       string
+	Time      string
+	Subject   string
+	Room      string
+	Teacher   string
+	Subgroup  string
+	UpdatedAt string // format with the update time
+	Page      string // format with the page number
+	Week      string // format with the first and the last day of the week
+	NoLessons string
+
+	Lecture  string
+	Practice string
+	Lab      string
+	Exam     string
+	Other    string
+}
+
+func (l Labels) lessonType(kind LessonType) string {
+	switch kind {
+	case LessonLecture:
+		return l.Lecture
+	case LessonPractice:
+		return l.Practice
+	case LessonLab:
+		return l.Lab
+	case LessonExam:
+		return l.Exam
+	}
+	return l.Other
+}
+
+func ClassifyLesson(name string) LessonType {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '(' || r == ')' || r == '.' || r == ',' || r == '-' || r == '/'
+	})
+	for _, word := range words {
+		for _, t := range lessonStyles {
+			for _, mark := range t.marks {
+				if word == mark {
+					return t.kind
+				}
+			}
+		}
+	}
+	return LessonOther
+}
+
+type fonts struct {
+	title   font.Face
+	bold    font.Face
+	regular font.Face
+	small   font.Face
+}
+
+func (f fonts) close() {
+	for _, face := range []font.Face{f.title, f.bold, f.regular, f.small} {
+		if face != nil {
+			face.Close()
+		}
+	}
+}
+
+var (
+	parseFontsOnce sync.Once
+	regularFont    *opentype.Font
+	boldFont       *opentype.Font
+	parseFontsErr  error
+)
+
+// loadFonts creates a fresh set of faces over the shared parsed fonts. Faces
+// cache glyphs and are not safe for concurrent use, so every render needs
+// its own.
+func loadFonts() (fonts, error) {
+	parseFontsOnce.Do(func() {
+		regularFont, parseFontsErr = opentype.Parse(goregular.TTF)
+		if parseFontsErr != nil {
+			parseFontsErr = fmt.Errorf("failed to parse regular font: %w", parseFontsErr)
+			return
+		}
+		boldFont, parseFontsErr = opentype.Parse(gobold.TTF)
+		if parseFontsErr != nil {
+			parseFontsErr = fmt.Errorf("failed to parse bold font: %w", parseFontsErr)
+		}
+	})
+	if parseFontsErr != nil {
+		return fonts{}, parseFontsErr
+	}
+
+	var err error
+	face := func(f *opentype.Font, size float64) font.Face {
+		if err != nil {
+			return nil
+		}
+		var face font.Face
+		face, err = opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		return face
+	}
+
+	loaded := fonts{
+		title:   face(boldFont, 24),
+		bold:    face(boldFont, 16),
+		regular: face(regularFont, 15),
+		small:   face(regularFont, 13),
+	}
+	if err != nil {
+		loaded.close()
+		return fonts{}, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return loaded, nil
+}
+
+type day struct {
+	name  string
+	date  string
+	cells map[string][]db.Schedule
+}
+
+type line struct {
+	text string
+	face font.Face
+	col  color.Color
+}
+
+func WeekImage(title string, schedules []db.Schedule, labels Labels) ([]byte, error) {
+	f, err := loadFonts()
+	if err != nil {
+		return nil, err
+	}
+	defer f.close()
+
+	var days []*day
+	dayIndex := make(map[string]*day)
+	slotSet := make(map[string]struct{})
+	for _, schedule := range schedules {
+		d, ok := dayIndex[schedule.LessonDate]
+		if !ok {
+			d = &day{name: schedule.DayOfWeek, date: schedule.LessonDate, cells: make(map[string][]db.Schedule)}
+			dayIndex[schedule.LessonDate] = d
+			days = append(days, d)
+		}
+		slot := strings.TrimSpace(schedule.LessonTime)
+		d.cells[slot] = append(d.cells[slot], schedule)
+		slotSet[slot] = struct{}{}
+	}
+
+	slots := make([]string, 0, len(slotSet))
+	for slot := range slotSet {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		return slotMinutes(slots[i]) < slotMinutes(slots[j])
+	})
+
+	lineHeight := func(face font.Face) int {
+		return face.Metrics().Height.Ceil() + 2
+	}
+
+	blockWidth := slotWidth - 2*padding - 6
+	blockLines := func(schedule db.Schedule) []line {
+		var lines []line
+		for _, text := range wrapText(f.bold, schedule.LessonName, blockWidth) {
+			lines = append(lines, line{text, f.bold, textColor})
+		}
+		details := []string{}
+		if schedule.Location != "" {
+			details = append(details, schedule.Location)
+		}
+		if schedule.Teacher != "" {
+			details = append(details, db.ShortTeacherName(schedule.Teacher))
+		}
+		if len(details) > 0 {
+			for _, text := range wrapText(f.regular, strings.Join(details, " · "), blockWidth) {
+				lines = append(lines, line{text, f.regular, mutedColor})
+			}
+		}
+		if schedule.Subgroup != "" {
+			lines = append(lines, line{labels.Subgroup + ": " + schedule.Subgroup, f.small, mutedColor})
+		}
+		return lines
+	}
+	blockHeight := func(lines []line) int {
+		height := padding
+		for _, l := range lines {
+			height += lineHeight(l.face)
+		}
+		return height + padding/2
+	}
+
+	rowHeights := make([]int, len(days))
+	for i, d := range days {
+		height := 2*lineHeight(f.bold) + 2*padding
+		for _, slot := range slots {
+			cellHeight := padding
+			for _, schedule := range d.cells[slot] {
+				cellHeight += blockHeight(blockLines(schedule)) + blockGap
+			}
+			if cellHeight > height {
+				height = cellHeight
+			}
+		}
+		rowHeights[i] = height
+	}
+
+	width := dayColumnWidth + len(slots)*slotWidth
+	if width < 480 {
+		width = 480
+	}
+	height := headerHeight + slotHeaderSize + legendHeight
+	for _, h := range rowHeights {
+		height += h
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), backgroundColor)
+
+	fill(img, image.Rect(0, 0, width, headerHeight), headerColor)
+	drawText(img, f.title, lightTextColor, padding*2, headerHeight/2+f.title.Metrics().Ascent.Ceil()/2-2, title)
+
+	y := headerHeight
+	fill(img, image.Rect(0, y, width, y+slotHeaderSize), dayColor)
+	for i, slot := range slots {
+		x := dayColumnWidth + i*slotWidth
+		drawText(img, f.bold, textColor, x+padding, y+slotHeaderSize/2+f.bold.Metrics().Ascent.Ceil()/2-2, slot)
+	}
+	hline(img, 0, width, y+slotHeaderSize-1, gridColor)
+	y += slotHeaderSize
+
+	for i, d := range days {
+		rowHeight := rowHeights[i]
+		fill(img, image.Rect(0, y, dayColumnWidth, y+rowHeight), dayColor)
+		drawText(img, f.bold, textColor, padding, y+padding+f.bold.Metrics().Ascent.Ceil(), d.name)
+		drawText(img, f.regular, mutedColor, padding, y+padding+lineHeight(f.bold)+f.regular.Metrics().Ascent.Ceil(), d.date)
+
+		for j, slot := range slots {
+			x := dayColumnWidth + j*slotWidth
+			blockY := y + padding
+			for _, schedule := range d.cells[slot] {
+				lines := blockLines(schedule)
+				bh := blockHeight(lines)
+				style := styleFor(ClassifyLesson(schedule.LessonName))
+
+				fill(img, image.Rect(x+padding, blockY, x+slotWidth-padding, blockY+bh), style.fill)
+				fill(img, image.Rect(x+padding, blockY, x+padding+4, blockY+bh), style.stripe)
+
+				textY := blockY + padding/2
+				for _, l := range lines {
+					drawText(img, l.face, l.col, x+padding+10, textY+l.face.Metrics().Ascent.Ceil(), l.text)
+					textY += lineHeight(l.face)
+				}
+				blockY += bh + blockGap
+			}
+			vline(img, x, y, y+rowHeight, gridColor)
+		}
+
+		y += rowHeight
+		hline(img, 0, width, y-1, gridColor)
+	}
+
+	x := padding * 2
+	legendY := y + legendHeight/2
+	for _, t := range lessonStyles {
+		title := labels.lessonType(t.kind)
+		fill(img, image.Rect(x, legendY-7, x+14, legendY+7), t.stripe)
+		x += 20
+		drawText(img, f.small, textColor, x, legendY+f.small.Metrics().Ascent.Ceil()/2-1, title)
+		x += font.MeasureString(f.small, title).Ceil() + 18
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func styleFor(kind LessonType) lessonStyle {
+	for _, style := range lessonStyles {
+		if style.kind == kind {
+			return style
+		}
+	}
+	return lessonStyles[len(lessonStyles)-1]
+}
+
+func slotMinutes(slot string) int {
+	start, _, _ := strings.Cut(slot, "-")
+	t, err := time.Parse("15:04", strings.ReplaceAll(strings.TrimSpace(start), ".", ":"))
+	if err != nil {
+		return 24 * 60
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+func wrapText(face font.Face, text string, width int) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && font.MeasureString(face, candidate).Ceil() > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func drawText(img draw.Image, face font.Face, col color.Color, x, y int, text string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+func fill(img draw.Image, rect image.Rectangle, col color.Color) {
+	draw.Draw(img, rect, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+func hline(img draw.Image, x1, x2, y int, col color.Color) {
+	fill(img, image.Rect(x1, y, x2, y+1), col)
+}
+
+func vline(img draw.Image, x, y1, y2 int, col color.Color) {
+	fill(img, image.Rect(x, y1, x+1, y2), col)
+}
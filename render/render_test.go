@@ -0,0 +1,35 @@
+package render
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+func TestWeekImageConcurrent(t *testing.T) {
+	schedules := []db.Schedule{
+		{LessonDate: "16.03", DayOfWeek: "Понедельник", LessonTime: "09:00-10:20", LessonName: "Математика (лк)", Location: "101", Teacher: "Иванов И.И."},
+		{LessonDate: "17.03", DayOfWeek: "Вторник", LessonTime: "10:35-11:55", LessonName: "Физика (лб)", Location: "202", Teacher: "Петров П.П.", Subgroup: "1"},
+	}
+	labels := Labels{Day: "Day", Time: "Time", UpdatedAt: "Updated %s", Week: "%s – %s", Lecture: "Lecture", Lab: "Lab", Other: "Other"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := WeekImage("Week", schedules, labels)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
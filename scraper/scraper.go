@@ -214,22 +214,26 @@ func visitWithRetry(c *colly.Collector, link string, maxRetries int, delay time.
 	return fmt.Errorf("failed to visit link %s after %d attempts", link, maxRetries)
 }
 
-func Start(dbConn *pg.DB) {
-	if err := scrapeAndUpdate(dbConn); err != nil {
+func Start(dbConn *pg.DB, onUpdate func()) {
+	if updated, err := scrapeAndUpdate(dbConn); err != nil {
 		fmt.Printf("Error during initial scraping and updating: %v\n", err)
+	} else if updated {
+		onUpdate()
 	}
 
 	ticker := time.NewTicker(30 * time.Minute)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		if err := scrapeAndUpdate(dbConn); err != nil {
+		if updated, err := scrapeAndUpdate(dbConn); err != nil {
 			fmt.Printf("Error during scraping and updating: %v\n", err)
+		} else if updated {
+			onUpdate()
 		}
 	}
 }
 
-func scrapeAndUpdate(dbConn *pg.DB) error {
+func scrapeAndUpdate(dbConn *pg.DB) (bool, error) {
 	c := colly.NewCollector(colly.UserAgent("Mozilla/5.0"))
 	c.SetRequestTimeout(60 * time.Second)
 
@@ -297,10 +301,10 @@ func processHTML(e *colly.HTMLElement, latestUpdate *time.Time, groups *[]string
 	return nil
 }
 
-func updateDatabaseIfNeeded(dbConn *pg.DB, latestUpdate time.Time, groups []string) error {
+func updateDatabaseIfNeeded(dbConn *pg.DB, latestUpdate time.Time, groups []string) (bool, error) {
 	lastUpdateDateFromDB, err := fetchLastUpdateDateFromDB(dbConn)
 	if err != nil {
-		return fmt.Errorf("failed to fetch last update date from database: %w", err)
+		return false, fmt.Errorf("failed to fetch last update date from database: %w", err)
 	}
 
 	fmt.Println("Date from DB: ", lastUpdateDateFromDB)
@@ -333,11 +337,12 @@ func updateDatabaseIfNeeded(dbConn *pg.DB, latestUpdate time.Time, groups []stri
 		<-done
 
 		if err := saveSchedulesToDB(dbConn, schedules, latestUpdate); err != nil {
-			return fmt.Errorf("failed to save schedules to database: %w", err)
+			return false, fmt.Errorf("failed to save schedules to database: %w", err)
 		}
 
 		fmt.Println("Schedules saved to database.")
+		return len(schedules) > 0, nil
 	}
 
-	return nil
+	return false, nil
 }
@@ -0,0 +1,225 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	eventBufferSize    = 1024
+	eventBatchSize     = 100
+	eventFlushInterval = 10 * time.Second
+	eventRetention     = 180 * 24 * time.Hour
+	statsTopLimit      = 10
+)
+
+// events is drained by recordEvents. Events are dropped rather than block
+// update handling when the buffer is full.
+var events = make(chan db.Event, eventBufferSize)
+
+func recordEvents(dbConn *pg.DB) {
+	ticker := time.NewTicker(eventFlushInterval)
+	defer ticker.Stop()
+
+	var batch []db.Event
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if _, err := dbConn.Model(&batch).Insert(); err != nil {
+			fmt.Printf("Failed to save %d events: %v\n", len(batch), err)
+		}
+		batch = nil
+	}
+
+	lastCleanup := time.Time{}
+	for {
+		select {
+		case event := <-events:
+			batch = append(batch, event)
+			if len(batch) >= eventBatchSize {
+				flush()
+			}
+		case now := <-ticker.C:
+			flush()
+			if now.Sub(lastCleanup) > 24*time.Hour {
+				deleteOldEvents(dbConn, now)
+				lastCleanup = now
+			}
+		}
+	}
+}
+
+func deleteOldEvents(dbConn *pg.DB, now time.Time) {
+	_, err := dbConn.Model((*db.Event)(nil)).Where("created_at < ?", now.Add(-eventRetention)).Delete()
+	if err != nil {
+		fmt.Printf("Failed to delete old events: %v\n", err)
+	}
+}
+
+func recordEvent(event db.Event) {
+	event.CreatedAt = time.Now()
+	select {
+	case events <- event:
+	default:
+	}
+}
+
+func trackEvent(c telebot.Context, kind, name, group string) {
+	event := db.Event{Kind: kind, Name: name, GroupName: group}
+	if c.Sender() != nil {
+		event.UserID = c.Sender().ID
+	}
+	recordEvent(event)
+}
+
+// errText and errTr are used for every error shown to users, so that failures
+// handlers recover from by replying still count as errors in /stats.
+func errText(c telebot.Context, key string, args ...interface{}) string {
+	trackEvent(c, db.EventError, key, "")
+	return t(c, key, args...)
+}
+
+func errTr(lang, key string, args ...interface{}) string {
+	recordEvent(db.Event{Kind: db.EventError, Name: key})
+	return tr(lang, key, args...)
+}
+
+func trackLatency(name string, start time.Time) {
+	recordEvent(db.Event{Kind: db.EventLatency, Name: name, Duration: time.Since(start)})
+}
+
+func trackUpdates(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		kind, name := updateEvent(c)
+		if kind != "" {
+			trackEvent(c, kind, name, "")
+		}
+
+		err := next(c)
+		if err != nil {
+			if name == "" {
+				name = "update"
+			}
+			trackEvent(c, db.EventError, name, "")
+		}
+		return err
+	}
+}
+
+func updateEvent(c telebot.Context) (string, string) {
+	switch {
+	case c.Callback() != nil:
+		return db.EventCallback, c.Callback().Unique
+	case c.Query() != nil:
+		return db.EventInline, "inline"
+	case c.Message() != nil && strings.HasPrefix(c.Text(), "/"):
+		command, _, _ := strings.Cut(c.Text(), " ")
+		command, _, _ = strings.Cut(command, "@")
+		command, _, _ = strings.Cut(command, "\n")
+		return db.EventCommand, command
+	}
+	return "", ""
+}
+
+func usageStatsText(dbConn *pg.DB, lang string, now time.Time) (string, error) {
+	var sb strings.Builder
+
+	for _, period := range []struct {
+		key   string
+		since time.Duration
+	}{
+		{"stats_dau", 24 * time.Hour},
+		{"stats_wau", 7 * 24 * time.Hour},
+		{"stats_mau", 30 * 24 * time.Hour},
+	} {
+		var count int
+		_, err := dbConn.QueryOne(pg.Scan(&count),
+			`SELECT count(DISTINCT user_id) FROM events WHERE user_id <> 0 AND created_at > ?`, now.Add(-period.since))
+		if err != nil {
+			return "", fmt.Errorf("failed to count active users: %w", err)
+		}
+		sb.WriteString(tr(lang, period.key, count) + "\n")
+	}
+
+	var groups []struct {
+		GroupName string
+		Count     int
+	}
+	err := dbConn.Model((*db.Users)(nil)).
+		Column("group_name").
+		ColumnExpr("count(*) AS count").
+		Where("group_name <> ''").
+		Where("blocked_at IS NULL").
+		Group("group_name").
+		Order("count DESC", "group_name").
+		Limit(statsTopLimit).
+		Select(&groups)
+	if err != nil {
+		return "", fmt.Errorf("failed to count users per group: %w", err)
+	}
+	sb.WriteString("\n" + bold(tr(lang, "stats_groups")) + "\n")
+	for _, group := range groups {
+		sb.WriteString(fmt.Sprintf("%s — %d\n", escapeHTML(group.GroupName), group.Count))
+	}
+
+	var features []struct {
+		Kind  string
+		Name  string
+		Count int
+	}
+	err = dbConn.Model((*db.Event)(nil)).
+		Column("kind", "name").
+		ColumnExpr("count(*) AS count").
+		Where("kind IN (?)", pg.In([]string{db.EventCommand, db.EventCallback, db.EventInline, db.EventView, db.EventExport})).
+		Where("created_at > ?", now.Add(-30*24*time.Hour)).
+		Group("kind", "name").
+		Order("count DESC", "name").
+		Limit(statsTopLimit).
+		Select(&features)
+	if err != nil {
+		return "", fmt.Errorf("failed to count features: %w", err)
+	}
+	sb.WriteString("\n" + bold(tr(lang, "stats_features")) + "\n")
+	for _, feature := range features {
+		sb.WriteString(fmt.Sprintf("%s %s — %d\n", feature.Kind, escapeHTML(feature.Name), feature.Count))
+	}
+
+	errorsCount, err := dbConn.Model((*db.Event)(nil)).
+		Where("kind = ?", db.EventError).
+		Where("created_at > ?", now.Add(-24*time.Hour)).
+		Count()
+	if err != nil {
+		return "", fmt.Errorf("failed to count errors: %w", err)
+	}
+	sb.WriteString("\n" + tr(lang, "stats_errors", errorsCount) + "\n")
+
+	var latency struct {
+		Count int
+		P50   float64
+		P95   float64
+		P99   float64
+	}
+	_, err = dbConn.QueryOne(&latency, `
+		SELECT count(*) AS count,
+			coalesce(percentile_cont(0.5) WITHIN GROUP (ORDER BY duration), 0) AS p50,
+			coalesce(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration), 0) AS p95,
+			coalesce(percentile_cont(0.99) WITHIN GROUP (ORDER BY duration), 0) AS p99
+		FROM events
+		WHERE kind = ? AND name IN (?) AND created_at > ?`,
+		db.EventLatency, pg.In([]string{"day", "week"}), now.Add(-7*24*time.Hour))
+	if err != nil {
+		return "", fmt.Errorf("failed to compute latency: %w", err)
+	}
+	sb.WriteString(tr(lang, "stats_latency", latency.Count,
+		time.Duration(latency.P50).Round(time.Microsecond),
+		time.Duration(latency.P95).Round(time.Microsecond),
+		time.Duration(latency.P99).Round(time.Microsecond)) + "\n")
+
+	return sb.String(), nil
+}
@@ -0,0 +1,51 @@
+package telegram_bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+// sendToUser is used for every message the bot sends on its own initiative.
+// Users who blocked the bot are marked so that push jobs skip them until
+// they come back with /start.
+func sendToUser(bot *telebot.Bot, dbConn *pg.DB, userID int64, what interface{}, opts ...interface{}) (*telebot.Message, error) {
+	message, err := sendThrottled(bot, userID, what, opts...)
+	if isBlockedError(err) {
+		markBlocked(dbConn, userID, time.Now())
+	}
+	return message, err
+}
+
+func isBlockedError(err error) bool {
+	return errors.Is(err, telebot.ErrBlockedByUser) ||
+		errors.Is(err, telebot.ErrUserIsDeactivated) ||
+		errors.Is(err, telebot.ErrNotStartedByUser)
+}
+
+func markBlocked(dbConn *pg.DB, userID int64, now time.Time) {
+	_, err := dbConn.Model((*db.Users)(nil)).
+		Set("blocked_at = ?", now).
+		Where("telegram_id = ?", userID).
+		Where("blocked_at IS NULL").
+		Update()
+	if err != nil {
+		fmt.Printf("Failed to mark user %d as blocked: %v\n", userID, err)
+	}
+}
+
+func reactivateUser(dbConn *pg.DB, user *db.Users) {
+	if user == nil || user.BlockedAt.IsZero() {
+		return
+	}
+
+	user.BlockedAt = time.Time{}
+	_, err := dbConn.Model(user).Set("blocked_at = NULL").WherePK().Update()
+	if err != nil {
+		fmt.Printf("Failed to reactivate user %d: %v\n", user.TelegramID, err)
+	}
+}
@@ -0,0 +1,343 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	activeUserPeriod = 30 * 24 * time.Hour
+	lastSeenInterval = time.Hour
+
+	broadcastRetryInterval = time.Minute
+)
+
+func handleBroadcasts(bot *telebot.Bot, dbConn *pg.DB, wake chan<- struct{}) {
+	admin := bot.Group()
+	admin.Use(privateOnly, requireRole(db.RoleAdmin))
+
+	admin.Handle("/broadcast", func(c telebot.Context) error {
+		return handleBroadcastCommand(c, dbConn)
+	})
+
+	admin.Handle(&telebot.Btn{Unique: "broadcast_send"}, func(c telebot.Context) error {
+		id, _ := strconv.ParseInt(c.Data(), 10, 64)
+		recipients, err := startBroadcast(dbConn, id, time.Now())
+		if err != nil {
+			return c.Edit(errText(c, "err_broadcast", escapeHTML(err.Error())))
+		}
+		wakeBroadcasts(wake)
+		return c.Edit(t(c, "broadcast_started", recipients))
+	})
+
+	admin.Handle(&telebot.Btn{Unique: "broadcast_cancel"}, func(c telebot.Context) error {
+		id, _ := strconv.ParseInt(c.Data(), 10, 64)
+		_, err := dbConn.Model((*db.Broadcast)(nil)).
+			Set("status = ?", db.BroadcastCancelled).
+			Where("id = ?", id).
+			Where("status = ?", db.BroadcastDraft).
+			Update()
+		if err != nil {
+			return c.Edit(errText(c, "err_broadcast", escapeHTML(err.Error())))
+		}
+		return c.Edit(t(c, "broadcast_cancelled"))
+	})
+}
+
+// handleBroadcastCommand expects the targeting on the command line and the
+// message itself on the following lines:
+//
+//	/broadcast active year=22 spec=ИТ
+//	Text of the announcement
+func handleBroadcastCommand(c telebot.Context, dbConn *pg.DB) error {
+	header, text, _ := strings.Cut(c.Text(), "\n")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return c.Send(t(c, "broadcast_usage"))
+	}
+
+	broadcast := &db.Broadcast{
+		AdminID:   c.Sender().ID,
+		Text:      text,
+		Status:    db.BroadcastDraft,
+		CreatedAt: time.Now(),
+	}
+
+	for _, filter := range strings.Fields(header)[1:] {
+		key, value, found := strings.Cut(filter, "=")
+		switch {
+		case filter == "all":
+		case filter == "active":
+			broadcast.ActiveOnly = true
+		case found && key == "year":
+			broadcast.Year = value
+		case found && key == "spec":
+			broadcast.Spec = value
+		default:
+			if found && key == "group" {
+				filter = value
+			}
+			group, err := findGroup(dbConn, filter)
+			if err != nil {
+				return c.Send(errText(c, "err_groups", escapeHTML(err.Error())))
+			}
+			if group == "" {
+				return c.Send(t(c, "group_not_found", escapeHTML(filter)))
+			}
+			broadcast.Groups = append(broadcast.Groups, group)
+		}
+	}
+
+	recipients, err := broadcastRecipients(dbConn, broadcast, time.Now())
+	if err != nil {
+		return c.Send(errText(c, "err_broadcast", escapeHTML(err.Error())))
+	}
+	if len(recipients) == 0 {
+		return c.Send(t(c, "broadcast_no_recipients"))
+	}
+
+	if _, err := dbConn.Model(broadcast).Insert(); err != nil {
+		return c.Send(errText(c, "err_broadcast", escapeHTML(err.Error())))
+	}
+
+	id := strconv.FormatInt(broadcast.ID, 10)
+	preview := t(c, "broadcast_preview", broadcastTargetText(langOf(c), broadcast), len(recipients)) +
+		"\n\n" + broadcastMessage(broadcast)
+	return c.Send(preview, createMenu(2,
+		createButton(t(c, "btn_broadcast_send"), "broadcast_send", id),
+		createButton(t(c, "btn_broadcast_cancel"), "broadcast_cancel", id),
+	))
+}
+
+func broadcastMessage(broadcast *db.Broadcast) string {
+	return escapeHTML(broadcast.Text)
+}
+
+func broadcastTargetText(lang string, broadcast *db.Broadcast) string {
+	var parts []string
+	switch {
+	case len(broadcast.Groups) > 0:
+		parts = append(parts, strings.Join(broadcast.Groups, ", "))
+	case broadcast.Year != "" || broadcast.Spec != "":
+		if broadcast.Year != "" {
+			parts = append(parts, tr(lang, "filter_year", broadcast.Year))
+		}
+		if broadcast.Spec != "" {
+			parts = append(parts, tr(lang, "filter_spec", broadcast.Spec))
+		}
+	default:
+		parts = append(parts, tr(lang, "broadcast_everyone"))
+	}
+	if broadcast.ActiveOnly {
+		parts = append(parts, tr(lang, "broadcast_active_only"))
+	}
+	return escapeHTML(strings.Join(parts, ", "))
+}
+
+func broadcastRecipients(dbConn *pg.DB, broadcast *db.Broadcast, now time.Time) ([]int64, error) {
+	var users []db.Users
+	query := dbConn.Model(&users).Column("telegram_id", "group_name").Where("blocked_at IS NULL")
+	if broadcast.ActiveOnly {
+		query = query.Where("last_seen_at > ?", now.Add(-activeUserPeriod))
+	}
+	if err := query.Order("telegram_id").Select(); err != nil {
+		return nil, fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	var recipients []int64
+	for _, user := range users {
+		if broadcastTargets(broadcast, user.GroupName) {
+			recipients = append(recipients, user.TelegramID)
+		}
+	}
+	return recipients, nil
+}
+
+func broadcastTargets(broadcast *db.Broadcast, group string) bool {
+	if len(broadcast.Groups) > 0 {
+		for _, g := range broadcast.Groups {
+			if g == group {
+				return true
+			}
+		}
+		return false
+	}
+
+	year, spec, _ := parseGroupName(group)
+	if broadcast.Year != "" && year != broadcast.Year {
+		return false
+	}
+	if broadcast.Spec != "" && !strings.EqualFold(spec, broadcast.Spec) {
+		return false
+	}
+	return true
+}
+
+func startBroadcast(dbConn *pg.DB, id int64, now time.Time) (int, error) {
+	broadcast := &db.Broadcast{ID: id}
+	if err := dbConn.Model(broadcast).WherePK().Select(); err != nil {
+		return 0, err
+	}
+	if broadcast.Status != db.BroadcastDraft {
+		return 0, errors.New("broadcast has already been sent or cancelled")
+	}
+
+	recipients, err := broadcastRecipients(dbConn, broadcast, now)
+	if err != nil {
+		return 0, err
+	}
+
+	deliveries := make([]db.BroadcastDelivery, 0, len(recipients))
+	for _, userID := range recipients {
+		deliveries = append(deliveries, db.BroadcastDelivery{
+			BroadcastID: id,
+			UserID:      userID,
+			Status:      db.DeliveryPending,
+		})
+	}
+
+	err = dbConn.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		res, err := tx.Model(broadcast).
+			Set("status = ?", db.BroadcastSending).
+			Set("started_at = ?", now).
+			WherePK().
+			Where("status = ?", db.BroadcastDraft).
+			Update()
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return errors.New("broadcast has already been sent or cancelled")
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		_, err = tx.Model(&deliveries).Insert()
+		return err
+	})
+	return len(deliveries), err
+}
+
+// wakeBroadcasts nudges runBroadcasts. Started broadcasts are stored with the
+// sending status, so a nudge dropped while the worker is busy loses nothing.
+func wakeBroadcasts(wake chan<- struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// runBroadcasts delivers broadcasts one at a time, oldest first. Broadcasts
+// interrupted by a restart or a failed load are picked up again on the next
+// tick; their pending deliveries are still stored.
+func runBroadcasts(bot *telebot.Bot, dbConn *pg.DB, wake <-chan struct{}) {
+	ticker := time.NewTicker(broadcastRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		var sending []int64
+		err := dbConn.Model((*db.Broadcast)(nil)).
+			Column("id").
+			Where("status = ?", db.BroadcastSending).
+			Order("id").
+			Select(&sending)
+		if err != nil {
+			fmt.Printf("Failed to load broadcasts to send: %v\n", err)
+		}
+		for _, id := range sending {
+			if err := deliverBroadcast(bot, dbConn, id); err != nil {
+				fmt.Printf("Failed to deliver broadcast %d: %v\n", id, err)
+			}
+		}
+
+		select {
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func deliverBroadcast(bot *telebot.Bot, dbConn *pg.DB, id int64) error {
+	broadcast := &db.Broadcast{ID: id}
+	if err := dbConn.Model(broadcast).WherePK().Select(); err != nil {
+		return err
+	}
+
+	var deliveries []db.BroadcastDelivery
+	err := dbConn.Model(&deliveries).
+		Where("broadcast_id = ?", id).
+		Where("status = ?", db.DeliveryPending).
+		Order("user_id").
+		Select()
+	if err != nil {
+		return err
+	}
+
+	text := broadcastMessage(broadcast)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		_, err := sendToUser(bot, dbConn, delivery.UserID, text)
+		switch {
+		case err == nil:
+			delivery.Status = db.DeliverySent
+			delivery.SentAt = time.Now()
+		case isBlockedError(err):
+			delivery.Status = db.DeliveryBlocked
+			delivery.Error = err.Error()
+		default:
+			delivery.Status = db.DeliveryFailed
+			delivery.Error = err.Error()
+		}
+
+		if _, err := dbConn.Model(delivery).Column("status", "error", "sent_at").WherePK().Update(); err != nil {
+			fmt.Printf("Failed to save delivery of broadcast %d to %d: %v\n", id, delivery.UserID, err)
+		}
+	}
+
+	_, err = dbConn.Model(broadcast).
+		Set("status = ?", db.BroadcastDone).
+		Set("finished_at = ?", time.Now()).
+		WherePK().
+		Update()
+	if err != nil {
+		return err
+	}
+
+	report, err := broadcastReport(dbConn, id)
+	if err != nil {
+		return err
+	}
+	_, err = sendToUser(bot, dbConn, broadcast.AdminID, report)
+	return err
+}
+
+func broadcastReport(dbConn *pg.DB, id int64) (string, error) {
+	var counts []struct {
+		Status string
+		Count  int
+	}
+	err := dbConn.Model((*db.BroadcastDelivery)(nil)).
+		Column("status").
+		ColumnExpr("count(*) AS count").
+		Where("broadcast_id = ?", id).
+		Group("status").
+		Select(&counts)
+	if err != nil {
+		return "", err
+	}
+
+	byStatus := make(map[string]int)
+	for _, count := range counts {
+		byStatus[count.Status] = count.Count
+	}
+	return tr(defaultLang, "broadcast_done", id,
+		byStatus[db.DeliverySent], byStatus[db.DeliveryBlocked], byStatus[db.DeliveryFailed]), nil
+}
@@ -0,0 +1,436 @@
+package telegram_bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const weeklyChannelPostHour = 18
+
+func handleChannels(bot *telebot.Bot, dbConn *pg.DB) {
+	personal := bot.Group()
+	personal.Use(privateOnly)
+
+	personal.Handle("/channel", func(c telebot.Context) error {
+		return handleChannelCommand(c, dbConn)
+	})
+}
+
+func handleChannelCommand(c telebot.Context, dbConn *pg.DB) error {
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(channelUsage(langOf(c)))
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return c.Send(channelUsage(langOf(c)))
+		}
+		return handleAddChannel(c, dbConn, args[1], args[2:])
+	case "remove":
+		if len(args) < 2 {
+			return c.Send(channelUsage(langOf(c)))
+		}
+		return handleRemoveChannel(c, dbConn, args[1])
+	case "list":
+		return handleListChannels(c, dbConn)
+	}
+
+	return c.Send(channelUsage(langOf(c)))
+}
+
+func channelUsage(lang string) string {
+	return tr(lang, "channel_usage")
+}
+
+func resolveChannel(bot *telebot.Bot, ref string) (*telebot.Chat, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return bot.ChatByID(id)
+	}
+	if !strings.HasPrefix(ref, "@") {
+		ref = "@" + ref
+	}
+	return bot.ChatByUsername(ref)
+}
+
+func checkChannelAccess(c telebot.Context, chat *telebot.Chat) error {
+	if chat.Type != telebot.ChatChannel {
+		return errors.New(t(c, "channel_not_channel", chat.Title))
+	}
+
+	member, err := c.Bot().ChatMemberOf(chat, c.Sender())
+	if err != nil {
+		return errors.New(t(c, "channel_err_user_rights", err))
+	}
+	if member.Role != telebot.Creator && member.Role != telebot.Administrator {
+		return errors.New(t(c, "channel_not_admin", chat.Title))
+	}
+
+	botMember, err := c.Bot().ChatMemberOf(chat, c.Bot().Me)
+	if err != nil {
+		return errors.New(t(c, "channel_err_bot_rights", err))
+	}
+	if botMember.Role != telebot.Administrator || !botMember.CanPostMessages {
+		return errors.New(t(c, "channel_bot_not_admin", chat.Title))
+	}
+
+	return nil
+}
+
+func handleAddChannel(c telebot.Context, dbConn *pg.DB, ref string, filters []string) error {
+	chat, err := resolveChannel(c.Bot(), ref)
+	if err != nil {
+		return c.Send(t(c, "channel_not_found", escapeHTML(ref), escapeHTML(err.Error())))
+	}
+	if err := checkChannelAccess(c, chat); err != nil {
+		return c.Send(errText(c, "err_generic", escapeHTML(err.Error())))
+	}
+
+	channel := &db.Channel{
+		ChatID:  chat.ID,
+		Title:   chat.Title,
+		AddedBy: c.Sender().ID,
+		AddedAt: time.Now(),
+	}
+
+	for _, filter := range filters {
+		key, value, found := strings.Cut(filter, "=")
+		switch {
+		case found && key == "year":
+			channel.Year = value
+		case found && key == "spec":
+			channel.Spec = value
+		default:
+			group, err := findGroup(dbConn, filter)
+			if err != nil {
+				return c.Send(errText(c, "err_groups", escapeHTML(err.Error())))
+			}
+			if group == "" {
+				return c.Send(t(c, "group_not_found", escapeHTML(filter)))
+			}
+			channel.Groups = append(channel.Groups, group)
+		}
+	}
+
+	if len(channel.Groups) == 0 && channel.Year == "" && channel.Spec == "" {
+		return c.Send(channelUsage(langOf(c)))
+	}
+
+	_, err = dbConn.Model(channel).
+		OnConflict("(chat_id) DO UPDATE").
+		Set("title = EXCLUDED.title").
+		Set("groups = EXCLUDED.groups").
+		Set("year = EXCLUDED.year").
+		Set("spec = EXCLUDED.spec").
+		Set("added_by = EXCLUDED.added_by").
+		Set("added_at = EXCLUDED.added_at").
+		Insert()
+	if err != nil {
+		return c.Send(errText(c, "err_save_channel", escapeHTML(err.Error())))
+	}
+
+	groups, err := getUniqueGroups(dbConn)
+	if err != nil {
+		return c.Send(errText(c, "err_groups", escapeHTML(err.Error())))
+	}
+
+	return c.Send(t(c, "channel_added",
+		escapeHTML(chat.Title), len(channelGroups(channel, groups)), weeklyChannelPostHour))
+}
+
+func handleRemoveChannel(c telebot.Context, dbConn *pg.DB, ref string) error {
+	chat, err := resolveChannel(c.Bot(), ref)
+	if err != nil {
+		return c.Send(t(c, "channel_not_found", escapeHTML(ref), escapeHTML(err.Error())))
+	}
+
+	var channel db.Channel
+	if err := dbConn.Model(&channel).Where("chat_id = ?", chat.ID).Select(); err != nil {
+		return c.Send(t(c, "channel_not_added", escapeHTML(chat.Title)))
+	}
+
+	if channel.AddedBy != c.Sender().ID {
+		if err := checkChannelAccess(c, chat); err != nil {
+			return c.Send(errText(c, "err_generic", escapeHTML(err.Error())))
+		}
+	}
+
+	if err := deleteChannel(dbConn, chat.ID); err != nil {
+		return c.Send(errText(c, "err_delete_channel", escapeHTML(err.Error())))
+	}
+
+	return c.Send(t(c, "channel_removed", escapeHTML(chat.Title)))
+}
+
+func handleListChannels(c telebot.Context, dbConn *pg.DB) error {
+	var channels []db.Channel
+	err := dbConn.Model(&channels).
+		Where("added_by = ?", c.Sender().ID).
+		Order("title").
+		Select()
+	if err != nil {
+		return c.Send(errText(c, "err_channels", escapeHTML(err.Error())))
+	}
+	if len(channels) == 0 {
+		return c.Send(t(c, "no_channels") + "\n\n" + channelUsage(langOf(c)))
+	}
+
+	var text strings.Builder
+	text.WriteString(t(c, "your_channels") + "\n")
+	for _, channel := range channels {
+		text.WriteString(fmt.Sprintf("\n%s — %s", escapeHTML(channel.Title), escapeHTML(channelFilterText(langOf(c), &channel))))
+	}
+	return c.Send(text.String())
+}
+
+func channelFilterText(lang string, channel *db.Channel) string {
+	if len(channel.Groups) > 0 {
+		return strings.Join(channel.Groups, ", ")
+	}
+
+	var parts []string
+	if channel.Year != "" {
+		parts = append(parts, tr(lang, "filter_year", channel.Year))
+	}
+	if channel.Spec != "" {
+		parts = append(parts, tr(lang, "filter_spec", channel.Spec))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func channelGroups(channel *db.Channel, allGroups []string) []string {
+	var groups []string
+	for _, group := range allGroups {
+		if len(channel.Groups) > 0 {
+			for _, g := range channel.Groups {
+				if g == group {
+					groups = append(groups, group)
+					break
+				}
+			}
+			continue
+		}
+
+		year, spec, _ := parseGroupName(group)
+		if channel.Year != "" && year != channel.Year {
+			continue
+		}
+		if channel.Spec != "" && !strings.EqualFold(spec, channel.Spec) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func deleteChannel(dbConn *pg.DB, chatID int64) error {
+	if _, err := dbConn.Model((*db.ChannelGroupState)(nil)).Where("channel_id = ?", chatID).Delete(); err != nil {
+		return err
+	}
+	_, err := dbConn.Model((*db.Channel)(nil)).Where("chat_id = ?", chatID).Delete()
+	return err
+}
+
+func publishWeeklyChannels(bot *telebot.Bot, dbConn *pg.DB, now time.Time) {
+	if now.Weekday() != time.Sunday || now.Hour() < weeklyChannelPostHour {
+		return
+	}
+
+	today := now.Format("02.01.2006")
+
+	var channels []db.Channel
+	err := dbConn.Model(&channels).
+		Where("last_weekly_post IS DISTINCT FROM ?", today).
+		Select()
+	if err != nil {
+		fmt.Printf("Failed to fetch channels: %v\n", err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	allGroups, err := getUniqueGroups(dbConn)
+	if err != nil {
+		fmt.Printf("Failed to fetch groups for channels: %v\n", err)
+		return
+	}
+
+	nextWeek := now.AddDate(0, 0, 7)
+	for i := range channels {
+		channel := &channels[i]
+
+		posted, err := postedGroups(dbConn, channel.ChatID)
+		if err != nil {
+			fmt.Printf("Failed to fetch channel state: %v\n", err)
+			continue
+		}
+
+		// The post is only marked as done once every group is delivered, so
+		// the next tick retries the groups that failed. Errors that retrying
+		// won't fix, like missing rights, end this week's post.
+		delivered := true
+		for _, group := range channelGroups(channel, allGroups) {
+			text, monday, err := channelWeekText(dbConn, group, nextWeek)
+			if err != nil {
+				fmt.Printf("Failed to build weekly schedule for %s: %v\n", group, err)
+				delivered = false
+				continue
+			}
+			if posted[group+"|"+monday.Format("02.01.2006")] {
+				continue
+			}
+
+			if text != "" {
+				header := "🗓 " + bold(tr(defaultLang, "group_header", group)) + " — " + tr(defaultLang, "week_schedule_from", monday.Format("02.01")) + "\n"
+				if err := sendSplit(bot, channel.ChatID, header+text); err != nil {
+					handleChannelError(dbConn, channel, err)
+					delivered = !isPermanentError(err)
+					break
+				}
+			}
+
+			if err := saveChannelState(dbConn, channel.ChatID, group, monday, textHash(text), true); err != nil {
+				fmt.Printf("Failed to save channel state: %v\n", err)
+			}
+		}
+		if !delivered {
+			continue
+		}
+
+		channel.LastWeeklyPost = today
+		if _, err := dbConn.Model(channel).Column("last_weekly_post").WherePK().Update(); err != nil {
+			fmt.Printf("Failed to save channel %d: %v\n", channel.ChatID, err)
+		}
+	}
+}
+
+func postedGroups(dbConn *pg.DB, channelID int64) (map[string]bool, error) {
+	var states []db.ChannelGroupState
+	err := dbConn.Model(&states).
+		Where("channel_id = ?", channelID).
+		Where("posted").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	posted := make(map[string]bool, len(states))
+	for _, state := range states {
+		posted[state.GroupName+"|"+state.WeekStart] = true
+	}
+	return posted, nil
+}
+
+func notifyChannelChanges(bot *telebot.Bot, dbConn *pg.DB, now time.Time) {
+	var channels []db.Channel
+	if err := dbConn.Model(&channels).Select(); err != nil {
+		fmt.Printf("Failed to fetch channels: %v\n", err)
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	allGroups, err := getUniqueGroups(dbConn)
+	if err != nil {
+		fmt.Printf("Failed to fetch groups for channels: %v\n", err)
+		return
+	}
+
+	for i := range channels {
+		channel := &channels[i]
+
+		var states []db.ChannelGroupState
+		if err := dbConn.Model(&states).Where("channel_id = ?", channel.ChatID).Select(); err != nil {
+			fmt.Printf("Failed to fetch channel state: %v\n", err)
+			continue
+		}
+		hashes := make(map[string]string, len(states))
+		for _, state := range states {
+			hashes[state.GroupName+"|"+state.WeekStart] = state.Hash
+		}
+
+	groups:
+		for _, group := range channelGroups(channel, allGroups) {
+			for _, day := range []time.Time{now, now.AddDate(0, 0, 7)} {
+				text, monday, err := channelWeekText(dbConn, group, day)
+				if err != nil {
+					fmt.Printf("Failed to build weekly schedule for %s: %v\n", group, err)
+					continue
+				}
+
+				hash := textHash(text)
+				previous, known := hashes[group+"|"+monday.Format("02.01.2006")]
+				if known && previous == hash {
+					continue
+				}
+
+				if known {
+					if text == "" {
+						text = tr(defaultLang, "no_lessons") + "\n"
+					}
+					header := "⚠️ " + bold(tr(defaultLang, "schedule_changes", group)) + " (" + tr(defaultLang, "week_from", monday.Format("02.01")) + ")\n"
+					if err := sendSplit(bot, channel.ChatID, header+text); err != nil {
+						handleChannelError(dbConn, channel, err)
+						break groups
+					}
+				}
+
+				if err := saveChannelState(dbConn, channel.ChatID, group, monday, hash, false); err != nil {
+					fmt.Printf("Failed to save channel state: %v\n", err)
+				}
+			}
+		}
+	}
+}
+
+func channelWeekText(dbConn *pg.DB, group string, day time.Time) (string, time.Time, error) {
+	schedules, monday, err := getWeeklySchedule(dbConn, group, "", day)
+	if err != nil {
+		return "", monday, err
+	}
+	if len(schedules) == 0 {
+		return "", monday, nil
+	}
+	return formatWeeklySchedule(schedules, db.ViewPrefs{}, defaultLang), monday, nil
+}
+
+func saveChannelState(dbConn *pg.DB, channelID int64, group string, monday time.Time, hash string, posted bool) error {
+	state := &db.ChannelGroupState{
+		ChannelID: channelID,
+		GroupName: group,
+		WeekStart: monday.Format("02.01.2006"),
+		Hash:      hash,
+		Posted:    posted,
+	}
+
+	query := dbConn.Model(state).
+		OnConflict("(channel_id, group_name, week_start) DO UPDATE").
+		Set("hash = EXCLUDED.hash")
+	if posted {
+		query = query.Set("posted = EXCLUDED.posted")
+	}
+	_, err := query.Insert()
+	return err
+}
+
+func handleChannelError(dbConn *pg.DB, channel *db.Channel, err error) {
+	fmt.Printf("Failed to publish to channel %d: %v\n", channel.ChatID, err)
+
+	if errors.Is(err, telebot.ErrKickedFromChannel) ||
+		errors.Is(err, telebot.ErrNotChannelMember) ||
+		errors.Is(err, telebot.ErrChatNotFound) {
+		if err := deleteChannel(dbConn, channel.ChatID); err != nil {
+			fmt.Printf("Failed to remove channel %d: %v\n", channel.ChatID, err)
+		}
+	}
+}
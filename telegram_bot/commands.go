@@ -0,0 +1,181 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+var botCommands = []string{"today", "tomorrow", "week", "next", "date", "find", "ics", "pdf", "export", "feed", "group", "channel"}
+
+func localizedCommands(lang string, names []string) []telebot.Command {
+	commands := make([]telebot.Command, 0, len(names))
+	for _, name := range names {
+		commands = append(commands, telebot.Command{Text: name, Description: tr(lang, "cmd_"+name)})
+	}
+	return commands
+}
+
+func setCommands(bot *telebot.Bot) {
+	groupScope := telebot.CommandScope{Type: telebot.CommandScopeAllGroupChats}
+	for _, language := range languages {
+		code := language.code
+		if code == defaultLang {
+			code = ""
+		}
+
+		if err := bot.SetCommands(localizedCommands(language.code, botCommands), code); err != nil {
+			fmt.Printf("Failed to set bot commands for %q: %v\n", language.code, err)
+		}
+		if err := bot.SetCommands(localizedCommands(language.code, groupChatCommands), groupScope, code); err != nil {
+			fmt.Printf("Failed to set group chat commands for %q: %v\n", language.code, err)
+		}
+	}
+}
+
+func handleTextCommands(bot *telebot.Bot, dbConn *pg.DB) {
+	bot.Handle("/today", func(c telebot.Context) error {
+		return sendDaySchedule(c, dbConn, time.Now())
+	})
+
+	bot.Handle("/tomorrow", func(c telebot.Context) error {
+		return sendDaySchedule(c, dbConn, time.Now().AddDate(0, 0, 1))
+	})
+
+	bot.Handle("/week", func(c telebot.Context) error {
+		return sendWeekSchedule(c, dbConn, time.Now())
+	})
+
+	bot.Handle("/next", func(c telebot.Context) error {
+		return handleNextCommand(c, dbConn)
+	})
+
+	bot.Handle("/date", func(c telebot.Context) error {
+		day, err := parseShortDate(c.Message().Payload, time.Now())
+		if err != nil {
+			query := parseQuery(c.Message().Payload, time.Now())
+			if query.kind != queryDay {
+				return c.Send(t(c, "date_usage"))
+			}
+			day = query.date
+		}
+		return sendDaySchedule(c, dbConn, day)
+	})
+
+	bot.Handle("/find", func(c telebot.Context) error {
+		return sendSubjectSearch(c, dbConn, c.Message().Payload)
+	})
+
+	bot.Handle("/group", func(c telebot.Context) error {
+		return handleGroupCommand(c, dbConn)
+	})
+}
+
+func sendDaySchedule(c telebot.Context, dbConn *pg.DB, day time.Time) error {
+	defer trackLatency("day", time.Now())
+	viewer, err := resolveViewer(c, dbConn, "")
+	if err != nil {
+		return c.Send(noGroupText(c))
+	}
+
+	text, err := dayScheduleText(dbConn, viewer, day)
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+	trackEvent(c, db.EventView, "day", viewer.groupName)
+
+	return c.Send(text, scheduleNowMenuButtons(day, viewer))
+}
+
+func sendWeekSchedule(c telebot.Context, dbConn *pg.DB, day time.Time) error {
+	defer trackLatency("week", time.Now())
+	viewer, err := resolveViewer(c, dbConn, "")
+	if err != nil {
+		return c.Send(noGroupText(c))
+	}
+
+	weeklySchedules, currentMonday, err := getWeeklySchedule(dbConn, viewer.groupName, viewer.subgroup, day)
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+
+	what, page, pages := weekScheduleView(viewer, weeklySchedules, currentMonday, 0)
+	trackEvent(c, db.EventView, "week", viewer.groupName)
+	return c.Send(what, scheduleWeekMenuButtons(currentMonday, viewer, page, pages))
+}
+
+func handleNextCommand(c telebot.Context, dbConn *pg.DB) error {
+	viewer, err := resolveViewer(c, dbConn, "")
+	if err != nil {
+		return c.Send(noGroupText(c))
+	}
+
+	lessons, err := getUpcomingLessons(dbConn, viewer.groupName, viewer.subgroup, time.Now())
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+	trackEvent(c, db.EventView, "next", viewer.groupName)
+	if len(lessons) == 0 {
+		return c.Send(t(c, "no_upcoming"))
+	}
+
+	return c.Send(viewer.obfuscate(t(c, "next_lesson") + "\n" + formatSchedule([]db.Schedule{lessons[0].schedule}, lessons[0].start, viewer.prefs, viewer.lang)))
+}
+
+func parseShortDate(dateStr string, now time.Time) (time.Time, error) {
+	dateStr = strings.TrimSpace(dateStr)
+
+	if t, err := time.ParseInLocation("02.01.2006", dateStr, now.Location()); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("02.01", dateStr, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format: %w", err)
+	}
+
+	return t.AddDate(now.Year()-t.Year(), 0, 0), nil
+}
+
+func findGroup(dbConn *pg.DB, query string) (string, error) {
+	uniqueGroups, err := getUniqueGroups(dbConn)
+	if err != nil {
+		return "", err
+	}
+
+	for _, group := range uniqueGroups {
+		if strings.EqualFold(group, strings.TrimSpace(query)) {
+			return group, nil
+		}
+	}
+	return "", nil
+}
+
+func handleGroupCommand(c telebot.Context, dbConn *pg.DB) error {
+	if isGroupChat(c.Chat()) {
+		return c.Send(t(c, "group_in_chat"))
+	}
+
+	query := strings.TrimSpace(c.Message().Payload)
+	if query == "" {
+		return c.Send(t(c, "group_usage"))
+	}
+
+	group, err := findGroup(dbConn, query)
+	if err != nil {
+		return c.Send(errText(c, "err_groups", escapeHTML(err.Error())))
+	}
+	if group == "" {
+		return c.Send(t(c, "group_not_found", escapeHTML(query)))
+	}
+
+	if err := saveUserGroup(dbConn, c.Sender().ID, group); err != nil {
+		return c.Send(errText(c, "err_save_group", escapeHTML(err.Error())))
+	}
+
+	return c.Send(t(c, "group_selected", escapeHTML(group)), mainMenuButtons(langOf(c), roleOf(c)))
+}
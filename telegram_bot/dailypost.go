@@ -0,0 +1,244 @@
+package telegram_bot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const dailyPostHour = 7
+
+func postDailySchedules(bot *telebot.Bot, dbConn *pg.DB, adminChats []int64, now time.Time) {
+	if now.Hour() < dailyPostHour {
+		return
+	}
+
+	today := now.Format("02.01.2006")
+
+	var bindings []db.ChatBinding
+	err := dbConn.Model(&bindings).
+		Where("auto_post").
+		Where("pinned_date IS DISTINCT FROM ?", today).
+		Select()
+	if err != nil {
+		fmt.Printf("Failed to fetch chat bindings: %v\n", err)
+		return
+	}
+
+	for i := range bindings {
+		binding := &bindings[i]
+
+		text, err := dailyPostText(dbConn, binding, now)
+		if err != nil {
+			fmt.Printf("Failed to build daily schedule for chat %d: %v\n", binding.ChatID, err)
+			continue
+		}
+
+		binding.PinnedDate = today
+		binding.PinnedMessageID = 0
+		binding.PinnedHash = textHash(text)
+
+		if text != "" {
+			messageID, err := sendAndPin(bot, binding.ChatID, text)
+			if err != nil {
+				handleDailyPostError(bot, dbConn, adminChats, binding, err)
+				continue
+			}
+			binding.PinnedMessageID = messageID
+		}
+
+		if err := saveDailyPost(dbConn, binding); err != nil {
+			fmt.Printf("Failed to save daily post for chat %d: %v\n", binding.ChatID, err)
+		}
+	}
+}
+
+func refreshDailySchedules(bot *telebot.Bot, dbConn *pg.DB, adminChats []int64, now time.Time) {
+	today := now.Format("02.01.2006")
+
+	var bindings []db.ChatBinding
+	err := dbConn.Model(&bindings).
+		Where("auto_post").
+		Where("pinned_date = ?", today).
+		Select()
+	if err != nil {
+		fmt.Printf("Failed to fetch chat bindings: %v\n", err)
+		return
+	}
+
+	for i := range bindings {
+		binding := &bindings[i]
+
+		text, err := dailyPostText(dbConn, binding, now)
+		if err != nil {
+			fmt.Printf("Failed to build daily schedule for chat %d: %v\n", binding.ChatID, err)
+			continue
+		}
+
+		hash := textHash(text)
+		if hash == binding.PinnedHash {
+			continue
+		}
+
+		switch {
+		case binding.PinnedMessageID == 0 && text == "":
+		case binding.PinnedMessageID == 0:
+			messageID, err := sendAndPin(bot, binding.ChatID, text)
+			if err != nil {
+				handleDailyPostError(bot, dbConn, adminChats, binding, err)
+				continue
+			}
+			binding.PinnedMessageID = messageID
+		default:
+			if text == "" {
+				text = "📌 " + tr(bindingLang(binding), "daily_no_lessons")
+			}
+
+			message := &telebot.StoredMessage{
+				MessageID: strconv.Itoa(binding.PinnedMessageID),
+				ChatID:    binding.ChatID,
+			}
+			_, err := throttled(binding.ChatID, func() (*telebot.Message, error) {
+				return bot.Edit(message, text)
+			})
+			switch {
+			case err == nil, errors.Is(err, telebot.ErrMessageNotModified), errors.Is(err, telebot.ErrSameMessageContent):
+			case isMessageNotFound(err):
+				messageID, err := sendAndPin(bot, binding.ChatID, text)
+				if err != nil {
+					handleDailyPostError(bot, dbConn, adminChats, binding, err)
+					continue
+				}
+				binding.PinnedMessageID = messageID
+			default:
+				handleDailyPostError(bot, dbConn, adminChats, binding, err)
+				continue
+			}
+		}
+
+		binding.PinnedHash = hash
+		if err := saveDailyPost(dbConn, binding); err != nil {
+			fmt.Printf("Failed to save daily post for chat %d: %v\n", binding.ChatID, err)
+		}
+	}
+}
+
+func dailyPostText(dbConn *pg.DB, binding *db.ChatBinding, day time.Time) (string, error) {
+	schedules, err := getSchedule(dbConn, binding.GroupName, day)
+	if err != nil {
+		return "", err
+	}
+	if len(schedules) == 0 {
+		return "", nil
+	}
+
+	lang := bindingLang(binding)
+	return "📌 " + groupHeader(lang, binding.GroupName) + formatSchedule(schedules, day, db.ViewPrefs{}, lang), nil
+}
+
+// bindingLang is the language of whoever bound the chat, saved with /bind.
+func bindingLang(binding *db.ChatBinding) string {
+	if isLanguage(binding.Language) {
+		return binding.Language
+	}
+	return defaultLang
+}
+
+func sendAndPin(bot *telebot.Bot, chatID int64, text string) (int, error) {
+	message, err := sendThrottled(bot, chatID, text)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := bot.Pin(message, telebot.Silent); err != nil {
+		fmt.Printf("Failed to pin daily schedule in chat %d: %v\n", chatID, err)
+	}
+
+	return message.ID, nil
+}
+
+func saveDailyPost(dbConn *pg.DB, binding *db.ChatBinding) error {
+	_, err := dbConn.Model(binding).
+		Column("pinned_message_id", "pinned_date", "pinned_hash").
+		WherePK().
+		Update()
+	return err
+}
+
+func handleDailyPostError(bot *telebot.Bot, dbConn *pg.DB, adminChats []int64, binding *db.ChatBinding, err error) {
+	fmt.Printf("Failed to post daily schedule to chat %d: %v\n", binding.ChatID, err)
+	if !isPermanentError(err) {
+		return
+	}
+
+	_, dbErr := dbConn.Model((*db.ChatBinding)(nil)).
+		Set("auto_post = FALSE").
+		Where("chat_id = ?", binding.ChatID).
+		Update()
+	if dbErr != nil {
+		fmt.Printf("Failed to disable daily posts for chat %d: %v\n", binding.ChatID, dbErr)
+		return
+	}
+
+	fmt.Printf("Disabled daily posts for chat %d\n", binding.ChatID)
+	text := tr(defaultLang, "daily_post_disabled", binding.ChatID, escapeHTML(binding.GroupName), escapeHTML(err.Error()))
+	for _, chatID := range adminChats {
+		if _, err := sendThrottled(bot, chatID, text); err != nil {
+			fmt.Printf("Failed to notify %d about disabled daily posts in %d: %v\n", chatID, binding.ChatID, err)
+		}
+	}
+}
+
+// chatGoneMarkers are the Telegram error descriptions that mean the bot can no
+// longer post to the chat at all. Other bad requests, such as a message that
+// fails to parse or is too long, are our own fault and must not disable posting.
+var chatGoneMarkers = []string{
+	"bot was kicked",
+	"chat not found",
+	"group chat was upgraded",
+	"not a member",
+	"have no rights",
+	"not enough rights",
+	"chat_write_forbidden",
+	"chat_admin_required",
+	"need administrator rights",
+}
+
+// isPermanentError reports whether the bot has lost access to the chat
+// (kicked, no rights, chat gone), so that retrying every minute won't help.
+func isPermanentError(err error) bool {
+	var floodErr telebot.FloodError
+	if err == nil || errors.As(err, &floodErr) {
+		return false
+	}
+
+	var groupErr telebot.GroupError
+	if errors.As(err, &groupErr) {
+		return true
+	}
+
+	description := strings.ToLower(err.Error())
+	for _, marker := range chatGoneMarkers {
+		if strings.Contains(description, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMessageNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "message to edit not found")
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package telegram_bot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gopkg.in/telebot.v3"
+)
+
+func TestIsPermanentError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"network", errors.New("dial tcp: i/o timeout"), false},
+		{"flood", telebot.FloodError{RetryAfter: 5}, false},
+		{"too many requests", telebot.NewError(429, "Too Many Requests"), false},
+		{"server error", fmt.Errorf("telegram: Internal Server Error (500)"), false},
+		{"kicked", telebot.ErrKickedFromGroup, true},
+		{"no rights", telebot.ErrNoRightsToSend, true},
+		{"chat not found", telebot.ErrChatNotFound, true},
+		{"wrapped", fmt.Errorf("send: %w", telebot.ErrNoRightsToSendPhoto), true},
+		{"write forbidden", fmt.Errorf("telegram: Bad Request: CHAT_WRITE_FORBIDDEN (400)"), true},
+		{"migrated", telebot.ErrGroupMigrated, true},
+		{"bad markup", fmt.Errorf("telegram: Bad Request: can't parse entities: unsupported start tag (400)"), false},
+		{"too long", telebot.ErrTooLongMessage, false},
+		{"unknown bad request", fmt.Errorf("telegram: Bad Request: wrong file identifier (400)"), false},
+		{"unknown forbidden", fmt.Errorf("telegram: Forbidden: bot is not a member of the channel chat (403)"), true},
+	}
+	for _, tt := range tests {
+		if got := isPermanentError(tt.err); got != tt.want {
+			t.Errorf("%s: isPermanentError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
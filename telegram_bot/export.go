@@ -0,0 +1,544 @@
+package telegram_bot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/Ah3ron/schedule-bot/export"
+	"github.com/Ah3ron/schedule-bot/feed"
+	"github.com/Ah3ron/schedule-bot/ical"
+	"github.com/Ah3ron/schedule-bot/render"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	termAutumn = "1"
+	termSpring = "2"
+	termAll    = "all"
+)
+
+func handleExports(bot *telebot.Bot, dbConn *pg.DB, feedURL string) {
+	exports := bot.Group()
+	exports.Use(denyBanned)
+	personal := bot.Group()
+	personal.Use(privateOnly, denyBanned)
+
+	exports.Handle("/ics", func(c telebot.Context) error {
+		term := strings.TrimSpace(c.Message().Payload)
+		switch term {
+		case "":
+			term = currentTerm(time.Now())
+		case termAutumn, termSpring, termAll:
+		default:
+			return c.Send(t(c, "ics_usage"))
+		}
+		return sendICS(c, dbConn, term)
+	})
+
+	bot.Handle(&telebot.Btn{Unique: "export_ics"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "ics_choose_term"), exportTermButtons("ics", langOf(c)))
+	})
+
+	exports.Handle(&telebot.Btn{Unique: "ics"}, func(c telebot.Context) error {
+		if err := c.Respond(); err != nil {
+			fmt.Printf("Failed to respond to callback: %v\n", err)
+		}
+		return sendICS(c, dbConn, c.Data())
+	})
+
+	exports.Handle("/pdf", func(c telebot.Context) error {
+		period, err := parseExportPeriod(c.Message().Payload, time.Now())
+		if err != nil {
+			return c.Send(t(c, "pdf_usage"))
+		}
+		return sendPDF(c, dbConn, period)
+	})
+
+	bot.Handle(&telebot.Btn{Unique: "export_pdf"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "pdf_choose_term"), exportTermButtons("pdf", langOf(c)))
+	})
+
+	exports.Handle(&telebot.Btn{Unique: "pdf"}, func(c telebot.Context) error {
+		if err := c.Respond(); err != nil {
+			fmt.Printf("Failed to respond to callback: %v\n", err)
+		}
+		return sendPDF(c, dbConn, exportPeriod{term: c.Data()})
+	})
+
+	exports.Handle("/export", func(c telebot.Context) error {
+		return handleExportCommand(c, dbConn)
+	})
+
+	personal.Handle("/feed", func(c telebot.Context) error {
+		text, markup := feedText(dbConn, c.Sender().ID, feedURL, langOf(c), false)
+		return c.Send(text, markup)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "ics_feed"}, func(c telebot.Context) error {
+		text, markup := feedText(dbConn, c.Sender().ID, feedURL, langOf(c), false)
+		return c.Edit(text, markup)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "ics_feed_new"}, func(c telebot.Context) error {
+		text, markup := feedText(dbConn, c.Sender().ID, feedURL, langOf(c), true)
+		return c.Edit(text, markup)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "ics_feed_revoke"}, func(c telebot.Context) error {
+		if err := setFeedToken(dbConn, c.Sender().ID, ""); err != nil {
+			return c.Edit(errText(c, "err_feed_revoke", escapeHTML(err.Error())), settingsMenuButtons(langOf(c)))
+		}
+		return c.Edit(t(c, "feed_revoked"), createMenu(1,
+			createButton(t(c, "btn_feed_create"), "ics_feed_new", ""),
+			createButton(t(c, "btn_back"), "settings", ""),
+		))
+	})
+}
+
+func feedText(dbConn *pg.DB, userID int64, feedURL, lang string, regenerate bool) (string, *telebot.ReplyMarkup) {
+	if feedURL == "" {
+		return tr(lang, "feed_unavailable"), settingsMenuButtons(lang)
+	}
+
+	user, err := getUserInfo(dbConn, userID)
+	if err != nil {
+		return tr(lang, "choose_group_in_settings"), settingsMenuButtons(lang)
+	}
+
+	token := user.FeedToken
+	if token == "" || regenerate {
+		token, err = newFeedToken()
+		if err == nil {
+			err = setFeedToken(dbConn, userID, token)
+		}
+		if err != nil {
+			return errTr(lang, "err_feed_create", escapeHTML(err.Error())), settingsMenuButtons(lang)
+		}
+	}
+
+	text := tr(lang, "feed_text", "<code>"+escapeHTML(feed.URL(feedURL, token))+"</code>")
+
+	return text, createMenu(1,
+		createButton(tr(lang, "btn_feed_new"), "ics_feed_new", ""),
+		createButton(tr(lang, "btn_feed_revoke"), "ics_feed_revoke", ""),
+		createButton(tr(lang, "btn_back"), "settings", ""),
+	)
+}
+
+func newFeedToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func setFeedToken(dbConn *pg.DB, userID int64, token string) error {
+	_, err := dbConn.Model((*db.Users)(nil)).
+		Set("feed_token = NULLIF(?, '')", token).
+		Where("telegram_id = ?", userID).
+		Update()
+	return err
+}
+
+func exportTermButtons(unique, lang string) *telebot.ReplyMarkup {
+	return createMenu(1,
+		createButton(tr(lang, "btn_term_autumn"), unique, termAutumn),
+		createButton(tr(lang, "btn_term_spring"), unique, termSpring),
+		createButton(tr(lang, "btn_term_all"), unique, termAll),
+		createButton(tr(lang, "btn_back"), "schedule", ""),
+	)
+}
+
+func sendICS(c telebot.Context, dbConn *pg.DB, term string) error {
+	viewer, err := resolveViewer(c, dbConn, "")
+	if err != nil {
+		return c.Send(noGroupText(c))
+	}
+
+	schedules, err := getGroupSchedules(dbConn, viewer.groupName)
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+	schedules = filterByTerm(filterBySubgroup(schedules, viewer.subgroup), term)
+	if len(schedules) == 0 {
+		return c.Send(t(c, "term_not_found"))
+	}
+
+	name := tr(viewer.lang, "group_header", viewer.groupName)
+	if viewer.subgroup != "" {
+		name += " (" + viewer.subgroup + ")"
+	}
+
+	now := time.Now()
+	calendar := ical.Build(name, schedules, now, now, calendarLabels(viewer.lang))
+	trackEvent(c, db.EventExport, "ics", viewer.groupName)
+
+	return c.Send(&telebot.Document{
+		File:     telebot.FromReader(bytes.NewReader(calendar)),
+		FileName: exportFileName(viewer, exportPeriod{term: term}, ".ics"),
+		MIME:     "text/calendar",
+		Caption:  escapeHTML(tr(viewer.lang, "ics_caption", viewer.groupName, termTitle(viewer.lang, term))),
+	})
+}
+
+type exportPeriod struct {
+	term     string
+	from, to time.Time
+}
+
+func parseExportPeriod(payload string, now time.Time) (exportPeriod, error) {
+	payload = strings.TrimSpace(payload)
+	switch payload {
+	case "":
+		return exportPeriod{term: currentTerm(now)}, nil
+	case termAutumn, termSpring, termAll:
+		return exportPeriod{term: payload}, nil
+	}
+
+	parts := strings.FieldsFunc(payload, func(r rune) bool {
+		return r == '-' || r == '–' || r == ' '
+	})
+	if len(parts) != 2 {
+		return exportPeriod{}, fmt.Errorf("invalid period %q", payload)
+	}
+
+	from, err := parseTermDate(parts[0], now)
+	if err != nil {
+		return exportPeriod{}, err
+	}
+	to, err := parseTermDate(parts[1], now)
+	if err != nil {
+		return exportPeriod{}, err
+	}
+	if to.Before(from) && !hasYear(parts[1]) {
+		to = to.AddDate(1, 0, 0)
+	}
+	if to.Before(from) {
+		return exportPeriod{}, fmt.Errorf("period ends before it starts")
+	}
+
+	return exportPeriod{from: from, to: to}, nil
+}
+
+// parseTermDate resolves a date without a year to the academic year the
+// lessons are in, like db.LessonYear does for the schedule itself.
+func parseTermDate(dateStr string, now time.Time) (time.Time, error) {
+	date, err := parseShortDate(dateStr, now)
+	if err != nil || hasYear(dateStr) {
+		return date, err
+	}
+	return date.AddDate(db.LessonYear(date.Format("02.01"), now)-date.Year(), 0, 0), nil
+}
+
+func hasYear(dateStr string) bool {
+	return strings.Count(strings.TrimSpace(dateStr), ".") == 2
+}
+
+func (p exportPeriod) title(lang string) string {
+	if p.term == "" {
+		return fmt.Sprintf("%s – %s", p.from.Format("02.01.2006"), p.to.Format("02.01.2006"))
+	}
+	return termTitle(lang, p.term)
+}
+
+func (p exportPeriod) bounds(now time.Time) (time.Time, time.Time) {
+	if p.term == "" {
+		return p.from, p.to
+	}
+
+	year := db.TermStartYear(now)
+	loc := now.Location()
+
+	switch p.term {
+	case termAutumn:
+		return time.Date(year, time.August, 1, 0, 0, 0, 0, loc), time.Date(year+1, time.January, 31, 0, 0, 0, 0, loc)
+	case termSpring:
+		return time.Date(year+1, time.February, 1, 0, 0, 0, 0, loc), time.Date(year+1, time.July, 31, 0, 0, 0, 0, loc)
+	}
+	return time.Time{}, time.Time{}
+}
+
+func (p exportPeriod) filter(schedules []db.Schedule, now time.Time) []db.Schedule {
+	if p.term != "" {
+		return filterByTerm(schedules, p.term)
+	}
+
+	filtered := make([]db.Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		date, err := time.ParseInLocation("02.01.2006", fmt.Sprintf("%s.%d", schedule.LessonDate, db.LessonYear(schedule.LessonDate, now)), p.from.Location())
+		if err != nil {
+			continue
+		}
+		if !date.Before(p.from) && !date.After(p.to) {
+			filtered = append(filtered, schedule)
+		}
+	}
+	return filtered
+}
+
+func sendPDF(c telebot.Context, dbConn *pg.DB, period exportPeriod) error {
+	viewer, err := resolveViewer(c, dbConn, "")
+	if err != nil {
+		return c.Send(noGroupText(c))
+	}
+
+	schedules, err := getGroupSchedules(dbConn, viewer.groupName)
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+
+	now := time.Now()
+	schedules = period.filter(filterBySubgroup(schedules, viewer.subgroup), now)
+	if len(schedules) == 0 {
+		return c.Send(t(c, "period_not_found"))
+	}
+
+	lastUpdate, err := getLastUpdate(dbConn)
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+
+	title := tr(viewer.lang, "pdf_title", viewer.groupName, period.title(viewer.lang))
+	if viewer.subgroup != "" {
+		title = tr(viewer.lang, "pdf_title_subgroup", viewer.groupName, viewer.subgroup, period.title(viewer.lang))
+	}
+
+	document, err := render.TimetablePDF(title, localWeekdays(viewer.lang, schedules), now, lastUpdate, renderLabels(viewer.lang))
+	if err != nil {
+		fmt.Printf("Failed to render PDF: %v\n", err)
+		trackEvent(c, db.EventError, "pdf", viewer.groupName)
+		return c.Send(t(c, "pdf_failed"))
+	}
+	trackEvent(c, db.EventExport, "pdf", viewer.groupName)
+
+	return c.Send(&telebot.Document{
+		File:     telebot.FromReader(bytes.NewReader(document)),
+		FileName: exportFileName(viewer, period, ".pdf"),
+		MIME:     "application/pdf",
+		Caption:  escapeHTML(title),
+	})
+}
+
+var exportFilterKeys = map[string]string{
+	"группа":  "group",
+	"група":   "group",
+	"group":   "group",
+	"преп":    "teacher",
+	"выкл":    "teacher",
+	"teacher": "teacher",
+	"ауд":     "room",
+	"аўд":     "room",
+	"room":    "room",
+}
+
+func handleExportCommand(c telebot.Context, dbConn *pg.DB) error {
+	args := strings.Fields(c.Message().Payload)
+	if len(args) == 0 {
+		return c.Send(t(c, "export_help"))
+	}
+
+	format := strings.ToLower(args[0])
+	if format != "csv" && format != "json" {
+		return c.Send(t(c, "export_help"))
+	}
+
+	now := time.Now()
+	period := exportPeriod{term: currentTerm(now)}
+	var filter export.Filter
+	for i := 1; i < len(args); i++ {
+		if key, value, ok := strings.Cut(args[i], ":"); ok {
+			field, known := exportFilterKeys[strings.ToLower(key)]
+			if !known {
+				return c.Send(t(c, "export_help"))
+			}
+			value = strings.TrimSpace(strings.Join(append([]string{value}, args[i+1:]...), " "))
+			switch field {
+			case "group":
+				group, err := findGroup(dbConn, value)
+				if err != nil || group == "" {
+					return c.Send(t(c, "group_not_found", escapeHTML(value)))
+				}
+				filter.Group = group
+			case "teacher":
+				filter.Teacher = value
+			case "room":
+				filter.Room = value
+			}
+			break
+		}
+
+		parsed, err := parseExportPeriod(args[i], now)
+		if err != nil {
+			return c.Send(t(c, "export_help"))
+		}
+		period = parsed
+	}
+
+	subgroup := ""
+	if filter.Group == "" && filter.Teacher == "" && filter.Room == "" {
+		viewer, err := resolveViewer(c, dbConn, "")
+		if err != nil {
+			return c.Send(noGroupText(c))
+		}
+		filter.Group = viewer.groupName
+		subgroup = viewer.subgroup
+	}
+	filter.From, filter.To = period.bounds(now)
+
+	records, err := export.Query(dbConn, filter, now)
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+	if subgroup != "" {
+		filtered := records[:0]
+		for _, record := range records {
+			if (db.Schedule{Subgroup: record.Subgroup}).AppliesTo(subgroup) {
+				filtered = append(filtered, record)
+			}
+		}
+		records = filtered
+	}
+	if len(records) == 0 {
+		return c.Send(t(c, "export_nothing"))
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, records, now); err != nil {
+		trackEvent(c, db.EventError, format, filter.Group)
+		return c.Send(errText(c, "err_export", escapeHTML(err.Error())))
+	}
+	trackEvent(c, db.EventExport, format, filter.Group)
+
+	name := filter.Group
+	if name == "" {
+		name = "schedule"
+	}
+	mime := "text/csv"
+	if format == "json" {
+		mime = "application/json"
+	}
+
+	return c.Send(&telebot.Document{
+		File:     telebot.FromReader(&buf),
+		FileName: exportFileName(scheduleViewer{groupName: name, subgroup: subgroup}, period, "."+format),
+		MIME:     mime,
+		Caption:  t(c, "export_caption", len(records)),
+	})
+}
+
+func getLastUpdate(dbConn *pg.DB) (time.Time, error) {
+	var lastUpdate time.Time
+	err := dbConn.Model((*db.Metadata)(nil)).ColumnExpr("MAX(last_update)").Select(&lastUpdate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last update: %w", err)
+	}
+	return lastUpdate, nil
+}
+
+func exportFileName(viewer scheduleViewer, period exportPeriod, ext string) string {
+	name := viewer.groupName
+	if viewer.subgroup != "" {
+		name += "_" + viewer.subgroup
+	}
+	switch period.term {
+	case "":
+		name += "_" + period.from.Format("02.01") + "-" + period.to.Format("02.01")
+	case termAll:
+	default:
+		name += "_term" + period.term
+	}
+	return strings.ReplaceAll(name, " ", "_") + ext
+}
+
+// FeedLabels localizes calendar feeds, which are served outside the bot.
+func FeedLabels(lang string) feed.Labels {
+	return feed.Labels{
+		Calendar: tr(lang, "group_header"),
+		Event:    calendarLabels(lang),
+	}
+}
+
+func calendarLabels(lang string) ical.Labels {
+	return ical.Labels{
+		Teacher:  tr(lang, "doc_teacher"),
+		Subgroup: tr(lang, "doc_subgroup"),
+		Group:    tr(lang, "doc_group"),
+	}
+}
+
+func renderLabels(lang string) render.Labels {
+	return render.Labels{
+		Day:       tr(lang, "doc_day"),
+		Time:      tr(lang, "doc_time"),
+		Subject:   tr(lang, "doc_subject"),
+		Room:      tr(lang, "doc_room"),
+		Teacher:   tr(lang, "doc_teacher"),
+		Subgroup:  tr(lang, "doc_subgroup"),
+		UpdatedAt: tr(lang, "doc_updated_at"),
+		Page:      tr(lang, "doc_page"),
+		Week:      tr(lang, "doc_week"),
+		NoLessons: tr(lang, "period_not_found"),
+		Lecture:   tr(lang, "lesson_lecture"),
+		Practice:  tr(lang, "lesson_practice"),
+		Lab:       tr(lang, "lesson_lab"),
+		Exam:      tr(lang, "lesson_exam"),
+		Other:     tr(lang, "lesson_other"),
+	}
+}
+
+// localWeekdays translates the weekday names stored with the schedule for
+// renderers that print them as is.
+func localWeekdays(lang string, schedules []db.Schedule) []db.Schedule {
+	localized := make([]db.Schedule, len(schedules))
+	for i, schedule := range schedules {
+		schedule.DayOfWeek = localDayOfWeek(lang, schedule.DayOfWeek)
+		localized[i] = schedule
+	}
+	return localized
+}
+
+func termTitle(lang, term string) string {
+	switch term {
+	case termAutumn:
+		return tr(lang, "term_autumn")
+	case termSpring:
+		return tr(lang, "term_spring")
+	}
+	return tr(lang, "term_all")
+}
+
+func currentTerm(now time.Time) string {
+	return lessonTerm(now.Month())
+}
+
+func lessonTerm(month time.Month) string {
+	if month >= time.February && month <= time.July {
+		return termSpring
+	}
+	return termAutumn
+}
+
+func filterByTerm(schedules []db.Schedule, term string) []db.Schedule {
+	if term == termAll {
+		return schedules
+	}
+
+	filtered := make([]db.Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		date, err := time.Parse("02.01", schedule.LessonDate)
+		if err != nil {
+			continue
+		}
+		if lessonTerm(date.Month()) == term {
+			filtered = append(filtered, schedule)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,79 @@
+package telegram_bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+func TestParseExportPeriod(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		payload  string
+		now      time.Time
+		from, to time.Time
+		term     string
+		wantErr  bool
+	}{
+		{name: "current term", payload: "", now: date(2024, time.October, 1), term: termAutumn},
+		{name: "named term", payload: termSpring, now: date(2024, time.October, 1), term: termSpring},
+		{name: "same month", payload: "01.10-15.10", now: date(2024, time.October, 1), from: date(2024, time.October, 1), to: date(2024, time.October, 15)},
+		{name: "across new year in december", payload: "01.12-15.01", now: date(2024, time.December, 20), from: date(2024, time.December, 1), to: date(2025, time.January, 15)},
+		{name: "across new year in january", payload: "01.12-15.01", now: date(2025, time.January, 10), from: date(2024, time.December, 1), to: date(2025, time.January, 15)},
+		{name: "spring dates in autumn", payload: "01.02-01.03", now: date(2024, time.October, 1), from: date(2025, time.February, 1), to: date(2025, time.March, 1)},
+		{name: "autumn dates in spring", payload: "01.10-15.10", now: date(2025, time.May, 1), from: date(2024, time.October, 1), to: date(2024, time.October, 15)},
+		{name: "explicit years", payload: "01.12.2024-15.01.2025", now: date(2025, time.May, 1), from: date(2024, time.December, 1), to: date(2025, time.January, 15)},
+		{name: "explicit years reversed", payload: "15.01.2025-01.12.2024", now: date(2025, time.May, 1), wantErr: true},
+		{name: "invalid date", payload: "32.13-01.01", now: date(2025, time.May, 1), wantErr: true},
+		{name: "single date", payload: "01.12", now: date(2025, time.May, 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, err := parseExportPeriod(tt.payload, tt.now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", period)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if period.term != tt.term || !period.from.Equal(tt.from) || !period.to.Equal(tt.to) {
+				t.Errorf("got %s %v – %v, want %s %v – %v", period.term, period.from, period.to, tt.term, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestExportPeriodFilter(t *testing.T) {
+	now := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	schedules := []db.Schedule{
+		{LessonDate: "01.10", LessonName: "Autumn"},
+		{LessonDate: "20.10", LessonName: "Later"},
+		{LessonDate: "15.03", LessonName: "Spring"},
+	}
+
+	period, err := parseExportPeriod("01.10-15.10", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filtered := period.filter(schedules, now)
+	if len(filtered) != 1 || filtered[0].LessonName != "Autumn" {
+		t.Errorf("range filter kept %+v, want only the autumn lesson", filtered)
+	}
+
+	from, to := exportPeriod{term: termAutumn}.bounds(now)
+	if want := time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("autumn term starts %v, want %v", from, want)
+	}
+	if want := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("autumn term ends %v, want %v", to, want)
+	}
+}
@@ -0,0 +1,262 @@
+package telegram_bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const reportDraftTTL = time.Hour
+
+func handleReports(bot *telebot.Bot, dbConn *pg.DB, cfg Config) {
+	personal := bot.Group()
+	personal.Use(privateOnly)
+
+	personal.Handle(&telebot.Btn{Unique: "report"}, func(c telebot.Context) error {
+		view, data, _ := strings.Cut(c.Data(), "_")
+		date, group, _ := parseViewData(data)
+
+		viewer, err := resolveViewer(c, dbConn, group)
+		if err != nil {
+			return c.Respond(&telebot.CallbackResponse{Text: t(c, "no_group_selected"), ShowAlert: true})
+		}
+		if date == "" {
+			date = time.Now().Format("02.01.2006")
+		}
+
+		report := &db.Report{
+			UserID:    c.Sender().ID,
+			GroupName: viewer.groupName,
+			View:      view,
+			ViewDate:  date,
+			Status:    db.ReportDraft,
+			CreatedAt: time.Now(),
+		}
+		if err := saveReportDraft(dbConn, report); err != nil {
+			return c.Respond(&telebot.CallbackResponse{Text: errText(c, "err_report", err.Error()), ShowAlert: true})
+		}
+
+		if err := c.Respond(); err != nil {
+			fmt.Printf("Failed to respond to callback: %v\n", err)
+		}
+		return c.Send(t(c, "report_prompt", escapeHTML(viewer.groupName), date), createMenu(1,
+			createButton(t(c, "btn_cancel"), "report_cancel", ""),
+		))
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "report_cancel"}, func(c telebot.Context) error {
+		_, err := dbConn.Model((*db.Report)(nil)).
+			Where("user_id = ?", c.Sender().ID).
+			Where("status = ?", db.ReportDraft).
+			Delete()
+		if err != nil {
+			return c.Edit(errText(c, "err_report", escapeHTML(err.Error())))
+		}
+		return c.Edit(t(c, "report_cancelled"))
+	})
+
+	admin := bot.Group()
+	admin.Use(requireRole(db.RoleAdmin))
+
+	admin.Handle("/reply", func(c telebot.Context) error {
+		_, rest := cutWord(c.Text())
+		idStr, text := cutWord(rest)
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || text == "" {
+			return c.Send(t(c, "reply_usage"))
+		}
+		return replyToReport(c, dbConn, id, text)
+	})
+
+	admin.Handle(&telebot.Btn{Unique: "report_close"}, func(c telebot.Context) error {
+		id, _ := strconv.ParseInt(c.Data(), 10, 64)
+		report, err := closeReport(dbConn, id, time.Now())
+		if err != nil {
+			return c.Respond(&telebot.CallbackResponse{Text: errText(c, "err_report", err.Error()), ShowAlert: true})
+		}
+
+		lang := userLangByID(dbConn, report.UserID)
+		if _, err := sendToUser(c.Bot(), dbConn, report.UserID, tr(lang, "report_closed", report.ID)); err != nil {
+			fmt.Printf("Failed to notify user %d about closed report %d: %v\n", report.UserID, report.ID, err)
+		}
+
+		if err := c.Respond(&telebot.CallbackResponse{Text: t(c, "report_closed_admin", report.ID)}); err != nil {
+			fmt.Printf("Failed to respond to callback: %v\n", err)
+		}
+		_, err = c.Bot().EditReplyMarkup(c.Message(), nil)
+		return err
+	})
+}
+
+func reportButton(view string, day time.Time, viewer scheduleViewer) []telebot.Btn {
+	if viewer.inGroupChat {
+		return nil
+	}
+	return createButton(tr(viewer.lang, "btn_report"), "report", view+"_"+viewData(day.Format("02.01.2006"), viewer.groupName))
+}
+
+// saveReportDraft replaces the user's previous draft and clears drafts that
+// expired without a comment, so abandoned reports don't pile up.
+func saveReportDraft(dbConn *pg.DB, report *db.Report) error {
+	_, err := dbConn.Model((*db.Report)(nil)).
+		Where("status = ?", db.ReportDraft).
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("user_id = ?", report.UserID).
+				WhereOr("created_at <= ?", report.CreatedAt.Add(-reportDraftTTL)), nil
+		}).
+		Delete()
+	if err != nil {
+		return err
+	}
+	_, err = dbConn.Model(report).Insert()
+	return err
+}
+
+// handleReportText runs before natural-language parsing: a user with a fresh
+// report draft is writing the comment, and an admin replying to a forwarded
+// report is answering it.
+func handleReportText(c telebot.Context, dbConn *pg.DB, cfg Config) (bool, error) {
+	if reply := c.Message().ReplyTo; reply != nil && reply.Sender != nil && reply.Sender.ID == c.Bot().Me.ID && roleOf(c) == db.RoleAdmin {
+		if id, ok := forwardedReportID(dbConn, c.Chat().ID, reply.ID); ok {
+			return true, replyToReport(c, dbConn, id, c.Text())
+		}
+	}
+
+	if isGroupChat(c.Chat()) {
+		return false, nil
+	}
+
+	report := &db.Report{}
+	err := dbConn.Model(report).
+		Where("user_id = ?", c.Sender().ID).
+		Where("status = ?", db.ReportDraft).
+		Where("created_at > ?", time.Now().Add(-reportDraftTTL)).
+		Order("id DESC").
+		Limit(1).
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		fmt.Printf("Failed to load report draft for %d: %v\n", c.Sender().ID, err)
+		return false, nil
+	}
+
+	report.Comment = strings.TrimSpace(c.Text())
+	report.Status = db.ReportOpen
+	if _, err := dbConn.Model(report).Column("comment", "status").WherePK().Update(); err != nil {
+		return true, c.Send(errText(c, "err_report", escapeHTML(err.Error())))
+	}
+
+	text := reportText(report, c.Sender())
+	markup := createMenu(1, createButton(tr(defaultLang, "btn_close_report"), "report_close", strconv.FormatInt(report.ID, 10)))
+	for _, chatID := range reportChats(cfg) {
+		msg, err := sendThrottled(c.Bot(), chatID, text, markup)
+		if err != nil {
+			fmt.Printf("Failed to forward report %d to %d: %v\n", report.ID, chatID, err)
+			continue
+		}
+		report.Forwards = append(report.Forwards, db.ReportForward{ChatID: chatID, MessageID: msg.ID})
+	}
+	if _, err := dbConn.Model(report).Column("forwards").WherePK().Update(); err != nil {
+		fmt.Printf("Failed to save forwards of report %d: %v\n", report.ID, err)
+	}
+
+	return true, c.Send(t(c, "report_sent", report.ID))
+}
+
+// forwardedReportID finds the report whose forwarded copy is the given
+// message, so that only replies to report forwards are sent to users.
+func forwardedReportID(dbConn *pg.DB, chatID int64, messageID int) (int64, bool) {
+	forward, err := json.Marshal([]db.ReportForward{{ChatID: chatID, MessageID: messageID}})
+	if err != nil {
+		return 0, false
+	}
+
+	report := &db.Report{}
+	err = dbConn.Model(report).
+		Column("id").
+		Where("forwards @> ?::jsonb", string(forward)).
+		Limit(1).
+		Select()
+	if err != nil {
+		if !errors.Is(err, pg.ErrNoRows) {
+			fmt.Printf("Failed to find report for message %d in %d: %v\n", messageID, chatID, err)
+		}
+		return 0, false
+	}
+	return report.ID, true
+}
+
+func reportChats(cfg Config) []int64 {
+	if cfg.AdminChatID != 0 {
+		return []int64{cfg.AdminChatID}
+	}
+	return cfg.AdminIDs
+}
+
+func reportText(report *db.Report, sender *telebot.User) string {
+	who := strconv.FormatInt(report.UserID, 10)
+	if sender.Username != "" {
+		who += " (@" + sender.Username + ")"
+	}
+	return tr(defaultLang, "report_forward", report.ID, escapeHTML(who), escapeHTML(report.GroupName),
+		tr(defaultLang, "report_view_"+report.View), report.ViewDate) +
+		"\n\n" + escapeHTML(report.Comment) +
+		"\n\n" + tr(defaultLang, "report_reply_hint", report.ID)
+}
+
+func replyToReport(c telebot.Context, dbConn *pg.DB, id int64, text string) error {
+	report := &db.Report{ID: id}
+	if err := dbConn.Model(report).WherePK().Select(); err != nil || report.Status == db.ReportDraft {
+		return c.Send(t(c, "report_not_found", id))
+	}
+
+	lang := userLangByID(dbConn, report.UserID)
+	message := tr(lang, "report_reply", report.ID) + "\n\n" + escapeHTML(strings.TrimSpace(text))
+	if _, err := sendToUser(c.Bot(), dbConn, report.UserID, message); err != nil {
+		return c.Send(errText(c, "err_report", escapeHTML(err.Error())))
+	}
+
+	if report.Status == db.ReportOpen {
+		report.Status = db.ReportAnswered
+		if _, err := dbConn.Model(report).Column("status").WherePK().Update(); err != nil {
+			fmt.Printf("Failed to update report %d: %v\n", report.ID, err)
+		}
+	}
+	return c.Send(t(c, "report_reply_sent", report.ID))
+}
+
+func closeReport(dbConn *pg.DB, id int64, now time.Time) (*db.Report, error) {
+	report := &db.Report{ID: id}
+	res, err := dbConn.Model(report).
+		Set("status = ?", db.ReportClosed).
+		Set("closed_at = ?", now).
+		WherePK().
+		Where("status IN (?)", pg.In([]string{db.ReportOpen, db.ReportAnswered})).
+		Update()
+	if err != nil {
+		return nil, err
+	}
+	if res.RowsAffected() == 0 {
+		return nil, errors.New("report is already closed")
+	}
+	return report, dbConn.Model(report).WherePK().Select()
+}
+
+func cutWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
@@ -0,0 +1,232 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+var groupChatCommands = []string{"today", "tomorrow", "week", "next", "date", "find", "ics", "pdf", "export", "bind", "unbind", "autopost"}
+
+func handleGroupChats(bot *telebot.Bot, dbConn *pg.DB) {
+	bot.Handle("/bind", func(c telebot.Context) error {
+		return handleBindCommand(c, dbConn)
+	})
+
+	bot.Handle("/unbind", func(c telebot.Context) error {
+		return handleUnbindCommand(c, dbConn)
+	})
+
+	bot.Handle("/autopost", func(c telebot.Context) error {
+		return handleAutoPostCommand(c, dbConn)
+	})
+
+	bot.Handle(telebot.OnAddedToGroup, func(c telebot.Context) error {
+		return c.Send(t(c, "chat_welcome"))
+	})
+}
+
+func isGroupChat(chat *telebot.Chat) bool {
+	return chat != nil && (chat.Type == telebot.ChatGroup || chat.Type == telebot.ChatSuperGroup)
+}
+
+func privateOnly(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		if !isGroupChat(c.Chat()) {
+			return next(c)
+		}
+
+		if c.Callback() != nil {
+			return c.Respond(&telebot.CallbackResponse{
+				Text:      t(c, "private_only_menu"),
+				ShowAlert: true,
+			})
+		}
+		return c.Send(t(c, "private_only_command"))
+	}
+}
+
+func resolveViewer(c telebot.Context, dbConn *pg.DB, groupName string) (scheduleViewer, error) {
+	if isGroupChat(c.Chat()) {
+		binding, err := getChatBinding(dbConn, c.Chat().ID)
+		if err != nil {
+			return scheduleViewer{}, err
+		}
+
+		viewer := scheduleViewer{groupName: binding.GroupName, inGroupChat: true, lang: langOf(c)}
+		if groupName != "" {
+			viewer.groupName = groupName
+		}
+		return viewer, nil
+	}
+
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return scheduleViewer{}, err
+	}
+	viewer := userViewer(user, groupName)
+	viewer.lang = langOf(c)
+	return viewer, nil
+}
+
+func noGroupText(c telebot.Context) string {
+	if isGroupChat(c.Chat()) {
+		return t(c, "chat_not_bound")
+	}
+	return t(c, "no_group")
+}
+
+func addressedText(c telebot.Context) (string, bool) {
+	text := c.Text()
+	mention := "@" + c.Bot().Me.Username
+
+	if idx := strings.Index(strings.ToLower(text), strings.ToLower(mention)); idx >= 0 {
+		return text[:idx] + text[idx+len(mention):], true
+	}
+
+	if reply := c.Message().ReplyTo; reply != nil && reply.Sender != nil && reply.Sender.ID == c.Bot().Me.ID {
+		return text, true
+	}
+
+	return text, false
+}
+
+func isChatAdmin(c telebot.Context) (bool, error) {
+	if senderChat := c.Message().SenderChat; senderChat != nil && senderChat.ID == c.Chat().ID {
+		return true, nil
+	}
+
+	member, err := c.Bot().ChatMemberOf(c.Chat(), c.Sender())
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch chat member: %w", err)
+	}
+
+	return member.Role == telebot.Creator || member.Role == telebot.Administrator, nil
+}
+
+func handleBindCommand(c telebot.Context, dbConn *pg.DB) error {
+	if !isGroupChat(c.Chat()) {
+		return c.Send(t(c, "bind_group_only"))
+	}
+
+	isAdmin, err := isChatAdmin(c)
+	if err != nil {
+		return c.Send(errText(c, "err_rights", escapeHTML(err.Error())))
+	}
+	if !isAdmin {
+		return c.Send(t(c, "bind_admin_only"))
+	}
+
+	query := strings.TrimSpace(c.Message().Payload)
+	if query == "" {
+		return c.Send(t(c, "bind_usage"))
+	}
+
+	group, err := findGroup(dbConn, query)
+	if err != nil {
+		return c.Send(errText(c, "err_groups", escapeHTML(err.Error())))
+	}
+	if group == "" {
+		return c.Send(t(c, "group_not_found", escapeHTML(query)))
+	}
+
+	binding := &db.ChatBinding{
+		ChatID:    c.Chat().ID,
+		GroupName: group,
+		BoundBy:   c.Sender().ID,
+		BoundAt:   time.Now(),
+		Language:  langOf(c),
+	}
+
+	_, err = dbConn.Model(binding).
+		OnConflict("(chat_id) DO UPDATE").
+		Set("group_name = EXCLUDED.group_name").
+		Set("bound_by = EXCLUDED.bound_by").
+		Set("bound_at = EXCLUDED.bound_at").
+		Set("language = EXCLUDED.language").
+		Set("pinned_message_id = NULL").
+		Set("pinned_date = NULL").
+		Set("pinned_hash = NULL").
+		Insert()
+	if err != nil {
+		return c.Send(errText(c, "err_save_group", escapeHTML(err.Error())))
+	}
+
+	return c.Send(t(c, "chat_bound", escapeHTML(group), dailyPostHour))
+}
+
+func handleUnbindCommand(c telebot.Context, dbConn *pg.DB) error {
+	if !isGroupChat(c.Chat()) {
+		return c.Send(t(c, "unbind_group_only"))
+	}
+
+	isAdmin, err := isChatAdmin(c)
+	if err != nil {
+		return c.Send(errText(c, "err_rights", escapeHTML(err.Error())))
+	}
+	if !isAdmin {
+		return c.Send(t(c, "unbind_admin_only"))
+	}
+
+	_, err = dbConn.Model((*db.ChatBinding)(nil)).
+		Where("chat_id = ?", c.Chat().ID).
+		Delete()
+	if err != nil {
+		return c.Send(errText(c, "err_unbind", escapeHTML(err.Error())))
+	}
+
+	return c.Send(t(c, "chat_unbound"))
+}
+
+func handleAutoPostCommand(c telebot.Context, dbConn *pg.DB) error {
+	if !isGroupChat(c.Chat()) {
+		return c.Send(t(c, "autopost_group_only"))
+	}
+
+	isAdmin, err := isChatAdmin(c)
+	if err != nil {
+		return c.Send(errText(c, "err_rights", escapeHTML(err.Error())))
+	}
+	if !isAdmin {
+		return c.Send(t(c, "autopost_admin_only"))
+	}
+
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(c.Message().Payload)) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return c.Send(t(c, "autopost_usage"))
+	}
+
+	res, err := dbConn.Model((*db.ChatBinding)(nil)).
+		Set("auto_post = ?", enabled).
+		Where("chat_id = ?", c.Chat().ID).
+		Update()
+	if err != nil {
+		return c.Send(errText(c, "err_save_setting", escapeHTML(err.Error())))
+	}
+	if res.RowsAffected() == 0 {
+		return c.Send(noGroupText(c))
+	}
+
+	if enabled {
+		return c.Send(t(c, "autopost_on", dailyPostHour))
+	}
+	return c.Send(t(c, "autopost_off"))
+}
+
+func getChatBinding(dbConn *pg.DB, chatID int64) (*db.ChatBinding, error) {
+	var binding db.ChatBinding
+	err := dbConn.Model(&binding).Where("chat_id = ?", chatID).Select()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat binding: %w", err)
+	}
+	return &binding, nil
+}
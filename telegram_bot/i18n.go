@@ -0,0 +1,177 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	langRU      = "ru"
+	langBE      = "be"
+	langEN      = "en"
+	defaultLang = langRU
+)
+
+var languages = []struct {
+	code string
+	name string
+}{
+	{langRU, "🇷🇺 Русский"},
+	{langBE, "🇧🇾 Беларуская"},
+	{langEN, "🇬🇧 English"},
+}
+
+var catalogs = map[string]map[string]string{
+	langRU: messagesRU,
+	langBE: messagesBE,
+	langEN: messagesEN,
+}
+
+var weekdayNames = map[string][7]string{
+	langRU: {"Воскресенье", "Понедельник", "Вторник", "Среда", "Четверг", "Пятница", "Суббота"},
+	langBE: {"Нядзеля", "Панядзелак", "Аўторак", "Серада", "Чацвер", "Пятніца", "Субота"},
+	langEN: {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+var monthNames = map[string][12]string{
+	langRU: {"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+	langBE: {"студзеня", "лютага", "сакавіка", "красавіка", "мая", "чэрвеня", "ліпеня", "жніўня", "верасня", "кастрычніка", "лістапада", "снежня"},
+	langEN: {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+func catalog(lang string) map[string]string {
+	if messages, ok := catalogs[lang]; ok {
+		return messages
+	}
+	return catalogs[defaultLang]
+}
+
+func tr(lang, key string, args ...interface{}) string {
+	text, ok := catalogs[lang][key]
+	if !ok {
+		text, ok = catalogs[defaultLang][key]
+	}
+	if !ok {
+		text = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+func t(c telebot.Context, key string, args ...interface{}) string {
+	return tr(langOf(c), key, args...)
+}
+
+func langOf(c telebot.Context) string {
+	if lang, ok := c.Get("lang").(string); ok {
+		return lang
+	}
+	if user := senderUser(c); user != nil && isLanguage(user.Language) {
+		return user.Language
+	}
+	if c.Sender() != nil {
+		return languageFromCode(c.Sender().LanguageCode)
+	}
+	return defaultLang
+}
+
+func userLang(user *db.Users) string {
+	if isLanguage(user.Language) {
+		return user.Language
+	}
+	return defaultLang
+}
+
+func userLangByID(dbConn *pg.DB, userID int64) string {
+	var user db.Users
+	if err := dbConn.Model(&user).Where("telegram_id = ?", userID).Select(); err != nil {
+		return defaultLang
+	}
+	return userLang(&user)
+}
+
+func languageFromCode(code string) string {
+	switch code {
+	case "":
+		return defaultLang
+	case langBE:
+		return langBE
+	case langRU, "uk", "kk":
+		return langRU
+	}
+	return langEN
+}
+
+func isLanguage(code string) bool {
+	_, ok := catalogs[code]
+	return ok
+}
+
+func saveUserLanguage(dbConn *pg.DB, userID int64, lang string) error {
+	user := &db.Users{TelegramID: userID, Language: lang}
+	_, err := dbConn.Model(user).
+		OnConflict("(telegram_id) DO UPDATE").
+		Set("language = EXCLUDED.language").
+		Insert()
+	return err
+}
+
+func weekdayName(lang string, weekday time.Weekday) string {
+	names, ok := weekdayNames[lang]
+	if !ok {
+		names = weekdayNames[defaultLang]
+	}
+	return names[weekday]
+}
+
+func localDayOfWeek(lang, dayOfWeek string) string {
+	weekday, ok := db.ParseWeekday(dayOfWeek)
+	if !ok {
+		return dayOfWeek
+	}
+	return weekdayName(lang, weekday)
+}
+
+func dayMonth(lang string, date time.Time) string {
+	names, ok := monthNames[lang]
+	if !ok {
+		names = monthNames[defaultLang]
+	}
+	return fmt.Sprintf("%d %s", date.Day(), names[date.Month()-1])
+}
+
+func languageButtons(lang string) *telebot.ReplyMarkup {
+	var rows [][]telebot.Btn
+	for _, language := range languages {
+		text := language.name
+		if language.code == lang {
+			text = "✓ " + text
+		}
+		rows = append(rows, createButton(text, "language", language.code))
+	}
+	rows = append(rows, createButton(tr(lang, "btn_back"), "settings", ""))
+	return createMenuRows(rows...)
+}
+
+func handleLanguage(c telebot.Context, dbConn *pg.DB) error {
+	lang := c.Data()
+	if lang == "" {
+		return c.Edit(t(c, "choose_language"), languageButtons(langOf(c)))
+	}
+	if !isLanguage(lang) {
+		return c.Respond()
+	}
+
+	if err := saveUserLanguage(dbConn, c.Sender().ID, lang); err != nil {
+		return c.Edit(errText(c, "err_save_setting", escapeHTML(err.Error())))
+	}
+	c.Set("lang", lang)
+
+	return c.Edit(t(c, "language_saved"), settingsMenuButtons(lang))
+}
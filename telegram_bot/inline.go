@@ -0,0 +1,179 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const inlineCacheTime = 60
+
+func handleInlineQueries(bot *telebot.Bot, dbConn *pg.DB) {
+	bot.Handle(telebot.OnQuery, func(c telebot.Context) error {
+		return handleInlineQuery(c, dbConn)
+	})
+}
+
+func handleInlineQuery(c telebot.Context, dbConn *pg.DB) error {
+	text := strings.TrimSpace(c.Query().Text)
+
+	viewer, rest, err := inlineViewer(c, dbConn, text)
+	if err != nil {
+		return c.Answer(&telebot.QueryResponse{
+			CacheTime:         inlineCacheTime,
+			IsPersonal:        true,
+			SwitchPMText:      t(c, "inline_choose_group"),
+			SwitchPMParameter: "inline",
+		})
+	}
+
+	viewer.lang = langOf(c)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var results telebot.Results
+	addDay := func(id, title string, day time.Time) error {
+		result, err := inlineDayResult(dbConn, viewer, id, title, day)
+		if err == nil {
+			results = append(results, result)
+		}
+		return err
+	}
+	addWeek := func(id, title string, day time.Time) error {
+		result, err := inlineWeekResult(dbConn, viewer, id, title, day)
+		if err == nil {
+			results = append(results, result)
+		}
+		return err
+	}
+
+	query := parseQuery(rest, now)
+	switch query.kind {
+	case queryDay:
+		err = addDay("day", t(c, "inline_on_date", query.date.Format("02.01")), query.date)
+		if err == nil {
+			err = addWeek("week", t(c, "inline_week"), query.date)
+		}
+	case queryWeek:
+		err = addWeek("week", t(c, "inline_week"), query.date)
+	case querySubject:
+		var result telebot.Result
+		result, err = inlineSubjectResult(dbConn, viewer, query.subject, now)
+		if err == nil {
+			results = append(results, result)
+		}
+	default:
+		err = addDay("today", t(c, "inline_today"), today)
+		if err == nil {
+			err = addDay("tomorrow", t(c, "inline_tomorrow"), today.AddDate(0, 0, 1))
+		}
+		if err == nil {
+			err = addWeek("week", t(c, "inline_this_week"), today)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Failed to answer inline query: %v\n", err)
+		return err
+	}
+
+	return c.Answer(&telebot.QueryResponse{
+		Results:    results,
+		CacheTime:  inlineCacheTime,
+		IsPersonal: true,
+	})
+}
+
+func inlineViewer(c telebot.Context, dbConn *pg.DB, text string) (scheduleViewer, string, error) {
+	first, rest, _ := strings.Cut(text, " ")
+	if first != "" {
+		group, err := findGroup(dbConn, first)
+		if err != nil {
+			return scheduleViewer{}, "", err
+		}
+		if group != "" {
+			viewer := scheduleViewer{groupName: group}
+			if user, err := getUserInfo(dbConn, c.Sender().ID); err == nil {
+				viewer = userViewer(user, group)
+			}
+			return viewer, strings.TrimSpace(rest), nil
+		}
+	}
+
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return scheduleViewer{}, "", err
+	}
+	return userViewer(user, ""), text, nil
+}
+
+func inlineDayResult(dbConn *pg.DB, viewer scheduleViewer, id, title string, day time.Time) (telebot.Result, error) {
+	schedules, err := getSchedule(dbConn, viewer.groupName, day)
+	if err != nil {
+		return nil, err
+	}
+	schedules = filterBySubgroup(schedules, viewer.subgroup)
+
+	header := groupHeader(viewer.lang, viewer.groupName)
+	text := header + tr(viewer.lang, "no_day_schedule", dayMonth(viewer.lang, day))
+	description := tr(viewer.lang, "inline_no_lessons")
+	if len(schedules) > 0 {
+		text = header + formatSchedule(schedules, day, viewer.prefs, viewer.lang)
+		description = tr(viewer.lang, "inline_day_description", len(schedules), lessonStartTime(schedules[0]))
+	}
+
+	return inlineArticle(id, fmt.Sprintf("%s — %s, %s", viewer.groupName, title, day.Format("02.01")), description, viewer.obfuscate(text)), nil
+}
+
+func inlineWeekResult(dbConn *pg.DB, viewer scheduleViewer, id, title string, day time.Time) (telebot.Result, error) {
+	schedules, monday, err := getWeeklySchedule(dbConn, viewer.groupName, viewer.subgroup, day)
+	if err != nil {
+		return nil, err
+	}
+
+	header := bold(tr(viewer.lang, "group_header", viewer.groupName)) + " — " + tr(viewer.lang, "week_from", monday.Format("02.01")) + "\n"
+	text := header + tr(viewer.lang, "no_week_schedule")
+	description := tr(viewer.lang, "inline_no_lessons")
+	if len(schedules) > 0 {
+		text = header + formatWeeklySchedule(schedules, viewer.prefs, viewer.lang)
+		description = tr(viewer.lang, "inline_week_description", len(schedules))
+	}
+
+	return inlineArticle(id, fmt.Sprintf("%s — %s", viewer.groupName, title), description, viewer.obfuscate(text)), nil
+}
+
+func inlineSubjectResult(dbConn *pg.DB, viewer scheduleViewer, subject string, now time.Time) (telebot.Result, error) {
+	lessons, err := getUpcomingLessons(dbConn, viewer.groupName, viewer.subgroup, now)
+	if err != nil {
+		return nil, err
+	}
+
+	found := findSubjectLessons(lessons, subject, subjectSearchLimit)
+	text := tr(viewer.lang, "subject_not_found", escapeHTML(subject))
+	description := tr(viewer.lang, "inline_nothing_found")
+	if len(found) > 0 {
+		text = groupHeader(viewer.lang, viewer.groupName) + formatSubjectSearch(found, subject, viewer.lang)
+		description = tr(viewer.lang, "inline_nearest", found[0].start.Format("02.01 15:04"))
+	}
+
+	return inlineArticle("find", fmt.Sprintf("%s — %s", viewer.groupName, subject), description, viewer.obfuscate(text)), nil
+}
+
+func inlineArticle(id, title, description, text string) telebot.Result {
+	result := &telebot.ArticleResult{
+		Title:       title,
+		Description: description,
+	}
+	result.SetResultID(id)
+	result.SetContent(&telebot.InputTextMessageContent{Text: splitMessage(text)[0]})
+	return result
+}
+
+func lessonStartTime(schedule db.Schedule) string {
+	start, _, _ := strings.Cut(schedule.LessonTime, "-")
+	return strings.TrimSpace(start)
+}
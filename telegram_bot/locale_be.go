@@ -0,0 +1,316 @@
+package telegram_bot
+
+var messagesBE = map[string]string{
+	"btn_schedule":             "📆 Расклад",
+	"btn_settings":             "⚙️ Налады",
+	"btn_information":          "ℹ️ Інфармацыя",
+	"btn_day":                  "📆 На дзень",
+	"btn_week":                 "📅 На тыдзень",
+	"btn_export_ics":           "🗓 Экспарт у каляндар",
+	"btn_export_pdf":           "🖨 PDF для друку",
+	"btn_back":                 "⬅️ Назад",
+	"btn_choose_group":         "🔄 Выбраць групу",
+	"btn_my_groups":            "📚 Мае групы",
+	"btn_subgroup":             "👥 Падгрупа",
+	"btn_view_prefs":           "🎨 Афармленне",
+	"btn_week_view":            "🖼 Выгляд тыдня",
+	"btn_ics_feed":             "🔗 Падпіска на каляндар",
+	"btn_language":             "🌐 Мова",
+	"btn_accept":               "Прыняць",
+	"btn_decline":              "Адмовіцца",
+	"btn_add_group":            "➕ Дадаць групу",
+	"btn_all_subgroups":        "Усе падгрупы",
+	"btn_week_text":            "📝 Тэкстам",
+	"btn_week_image":           "🖼 Малюнкам",
+	"btn_pref_layout":          "Фармат: %s",
+	"btn_pref_teachers":        "Выкладчыкі: %s",
+	"btn_pref_rooms":           "Аўдыторыі: %s",
+	"btn_pref_time":            "Час: %s",
+	"btn_pref_emoji":           "Эмодзі: %s",
+	"btn_reset":                "↩️ Скінуць",
+	"btn_feed_create":          "🔗 Стварыць новую спасылку",
+	"btn_feed_new":             "🔄 Новая спасылка",
+	"btn_feed_revoke":          "🚫 Адклікаць спасылку",
+	"btn_term_autumn":          "🍂 Першы семестр",
+	"btn_term_spring":          "🌱 Другі семестр",
+	"btn_term_all":             "📚 Увесь навучальны год",
+	"cmd_today":                "Расклад на сёння",
+	"cmd_tomorrow":             "Расклад на заўтра",
+	"cmd_week":                 "Расклад на тыдзень",
+	"cmd_next":                 "Наступная пара",
+	"cmd_date":                 "Расклад на дату, напрыклад /date 15.10",
+	"cmd_find":                 "Знайсці бліжэйшыя пары па прадмеце, напрыклад /find фізіка",
+	"cmd_ics":                  "Расклад у фармаце календара (.ics)",
+	"cmd_pdf":                  "Расклад для друку (PDF), напрыклад /pdf 01.09-31.10",
+	"cmd_export":               "Выгрузка раскладу ў CSV або JSON",
+	"cmd_feed":                 "Спасылка для падпіскі на каляндар",
+	"cmd_group":                "Выбраць групу, напрыклад /group 22ИТ-1",
+	"cmd_channel":              "Публікацыя раскладу ў канал",
+	"cmd_bind":                 "Прывязаць чат да групы, напрыклад /bind 22ИТ-1",
+	"cmd_unbind":               "Адвязаць чат ад групы",
+	"cmd_autopost":             "Штодзённая публікацыя раскладу: /autopost on або off",
+	"terms":                    "<b>Адмова ад адказнасці</b>\n\nІнфармацыя, якую дае бот, мае даведачны характар. Мы не нясём адказнасці за дакладнасць, паўнату або актуальнасць даных. Вы карыстаецеся інфармацыяй на ўласную рызыку.\n\nНацісніце кнопку ніжэй, каб прыняць правілы:",
+	"terms_accepted":           "Дзякуем, што прынялі ўмовы карыстання. Сардэчна запрашаем!",
+	"terms_declined":           "Каб карыстацца гэтым ботам, неабходна прыняць умовы карыстання",
+	"terms_updated":            "Умовы карыстання абнавіліся. Калі ласка, азнаёмцеся з імі і прыміце іх зноў.",
+	"err_accept_terms":         "Памылка захавання згоды: %s",
+	"inline_accept_terms":      "Прыміце ўмовы карыстання ў боце",
+	"main_menu":                "Галоўнае меню:",
+	"schedule_menu":            "Меню раскладу:",
+	"settings_menu":            "Налады:",
+	"information":              "Інфармацыя:",
+	"choose_language":          "Выберыце мову інтэрфейсу:",
+	"language_saved":           "Мова інтэрфейсу: беларуская.",
+	"group_header":             "Група %s",
+	"your_schedule":            "Ваш расклад",
+	"label_date":               "Дата:",
+	"label_time":               "Час:",
+	"label_lesson":             "Пара:",
+	"label_room":               "Аўдыт.:",
+	"label_teacher":            "Выкл.:",
+	"label_subgroup":           "Падгрупа:",
+	"search_title":             "Бліжэйшыя пары па запыце",
+	"render_failed":            "Не атрымалася адлюстраваць расклад.",
+	"week_from":                "тыдзень з %s",
+	"week_schedule_from":       "расклад на тыдзень з %s",
+	"schedule_changes":         "Змены ў раскладзе групы %s",
+	"no_lessons":               "Пар няма.",
+	"daily_no_lessons":         "Расклад на сёння змяніўся: пар няма.",
+	"daily_post_disabled":      "⚠️ Аўтапублікацыя раскладу ў чаце %d (група %s) адключана: %s",
+	"no_group_selected":        "Вы не выбралі групу для прагляду раскладу.",
+	"no_group":                 "Вы не выбралі групу. Пазначце яе камандай /group, напрыклад: /group 22ИТ-1",
+	"chat_not_bound":           "Гэты чат не прывязаны да групы. Адміністратар чата можа прывязаць яго камандай /bind, напрыклад: /bind 22ИТ-1",
+	"no_day_schedule":          "Расклад на %s не знойдзены",
+	"no_week_schedule":         "Расклад на гэты тыдзень не знойдзены.",
+	"no_upcoming":              "Бліжэйшых пар не знойдзена.",
+	"next_lesson":              "Наступная пара:",
+	"date_usage":               "Пазначце дату ў фармаце ДД.ММ, напрыклад: /date 15.10",
+	"find_usage":               "Пазначце назву прадмета, напрыклад: /find вышэйшая матэматыка",
+	"query_not_understood":     "Не атрымалася распазнаць запыт. Паспрабуйце, напрыклад: «заўтра», «пт», «наступны аўторак», «15.10», «тыдзень» або «калі фізіка».",
+	"subject_not_found":        "Пара «%s» у бліжэйшым раскладзе не знойдзена.",
+	"err_generic":              "Памылка: %s",
+	"err_schedule":             "Памылка атрымання раскладу: %s",
+	"err_groups":               "Памылка атрымання груп: %s",
+	"err_subgroups":            "Памылка атрымання падгруп: %s",
+	"err_save_group":           "Памылка захавання групы: %s",
+	"err_save_subgroup":        "Памылка захавання падгрупы: %s",
+	"err_save_setting":         "Памылка захавання налады: %s",
+	"err_spec_data":            "Памылка: некарэктныя даныя спецыяльнасці.",
+	"choose_year":              "Выберыце год паступлення:",
+	"choose_spec":              "Выберыце паток:",
+	"choose_group":             "Выберыце групу:",
+	"choose_group_first":       "Спачатку выберыце групу.",
+	"choose_group_in_settings": "Спачатку выберыце групу ў наладах.",
+	"group_selected":           "Вашу групу паспяхова выбрана: %s",
+	"group_not_found":          "Група %s не знойдзена.",
+	"group_usage":              "Пазначце групу, напрыклад: /group 22ИТ-1",
+	"group_in_chat":            "У групавым чаце групу задае адміністратар камандай /bind, напрыклад: /bind 22ИТ-1",
+	"no_saved_groups":          "У вас пакуль няма захаваных груп.",
+	"my_groups":                "Вашы групы (⭐ — асноўная).\nНацісніце на групу, каб зрабіць яе асноўнай:",
+	"group_not_saved":          "Гэтая група не захавана.",
+	"primary_group":            "Асноўная група: %s",
+	"cannot_remove_last":       "Нельга выдаліць адзіную групу.",
+	"group_removed":            "Групу %s выдалена. Асноўная група: %s",
+	"no_subgroups":             "У групы %s няма заняткаў па падгрупах.",
+	"subgroup_all":             "усе",
+	"choose_subgroup":          "Выберыце падгрупу (цяпер: %s):",
+	"subgroup_cleared":         "Цяпер паказваюцца пары ўсіх падгруп.",
+	"subgroup_selected":        "Вашу падгрупу паспяхова выбрана: %s",
+	"view_prefs":               "Афармленне раскладу. Па змаўчанні расклад на дзень паказваецца падрабязна, а на тыдзень — кампактна, са скарочанымі імёнамі выкладчыкаў.",
+	"pref_default":             "па змаўчанні",
+	"pref_compact":             "кампактны",
+	"pref_detailed":            "падрабязны",
+	"pref_full":                "цалкам",
+	"pref_short":               "скарочана",
+	"pref_start":               "пачатак пары",
+	"pref_range":               "пачатак і канец",
+	"pref_show":                "паказваць",
+	"pref_hide":                "хаваць",
+	"pref_on":                  "укл.",
+	"pref_off":                 "выкл.",
+	"choose_week_view":         "Як паказваць расклад на тыдзень?",
+	"week_view_text":           "Расклад на тыдзень будзе паказвацца тэкстам.",
+	"week_view_image":          "Расклад на тыдзень будзе паказвацца малюнкам.",
+	"private_only_menu":        "Гэтае меню даступнае толькі ў асабістых паведамленнях з ботам.",
+	"private_only_command":     "Гэтая каманда даступная толькі ў асабістых паведамленнях з ботам. У чаце выкарыстоўвайце /today, /week або /bind.",
+	"chat_welcome":             "Прывітанне! Адміністратар чата можа прывязаць яго да навучальнай групы камандай /bind, напрыклад: /bind 22ИТ-1. Пасля гэтага ў чаце будуць працаваць /today, /tomorrow, /week і /next.",
+	"bind_group_only":          "Каманда /bind працуе толькі ў групавых чатах. У асабістых паведамленнях выкарыстоўвайце /group.",
+	"bind_admin_only":          "Прывязаць чат да групы можа толькі адміністратар чата.",
+	"bind_usage":               "Пазначце групу, напрыклад: /bind 22ИТ-1",
+	"chat_bound":               "Чат прывязаны да групы %s. Цяпер тут працуюць /today, /tomorrow, /week і /next, а кожную раніцу ў %d:00 бот будзе публікаваць і замацоўваць расклад на дзень (адключыць: /autopost off).",
+	"err_rights":               "Памылка праверкі правоў: %s",
+	"unbind_group_only":        "Каманда /unbind працуе толькі ў групавых чатах.",
+	"unbind_admin_only":        "Адвязаць чат ад групы можа толькі адміністратар чата.",
+	"err_unbind":               "Памылка адвязкі чата: %s",
+	"chat_unbound":             "Чат адвязаны ад групы.",
+	"autopost_group_only":      "Каманда /autopost працуе толькі ў групавых чатах.",
+	"autopost_admin_only":      "Наладжваць публікацыю можа толькі адміністратар чата.",
+	"autopost_usage":           "Пазначце on або off, напрыклад: /autopost off",
+	"autopost_on":              "Штодзённая публікацыя раскладу ўключана (у %d:00).",
+	"autopost_off":             "Штодзённая публікацыя раскладу адключана.",
+	"channel_usage":            "Публікацыя раскладу ў канал:\n\n/channel add @канал 22ИТ-1 22ИТ-2 — публікаваць расклад выбраных груп\n/channel add @канал year=22 spec=ИТ — публікаваць расклад усіх груп набору 22 года патоку ИТ\n/channel remove @канал — спыніць публікацыю\n/channel list — вашы каналы\n\nБот павінен быць адміністратарам канала з правам публікацыі паведамленняў.",
+	"channel_not_channel":      "%s не з'яўляецца каналам",
+	"channel_err_user_rights":  "не атрымалася праверыць вашы правы ў канале: %v",
+	"channel_not_admin":        "вы не з'яўляецеся адміністратарам канала %s",
+	"channel_err_bot_rights":   "не атрымалася праверыць правы бота ў канале: %v",
+	"channel_bot_not_admin":    "бот павінен быць адміністратарам канала %s з правам публікацыі паведамленняў",
+	"channel_not_found":        "Канал %s не знойдзены: %s",
+	"channel_added":            "Канал %s падключаны. Груп пад фільтр: %d. Расклад на тыдзень будзе публікавацца па нядзелях у %d:00, а змены — пасля кожнага абнаўлення.",
+	"channel_not_added":        "Канал %s не падключаны.",
+	"channel_removed":          "Публікацыя ў канал %s адключана.",
+	"err_save_channel":         "Памылка захавання канала: %s",
+	"err_delete_channel":       "Памылка выдалення канала: %s",
+	"err_channels":             "Памылка атрымання каналаў: %s",
+	"no_channels":              "Вы пакуль не падключылі ніводнага канала.",
+	"your_channels":            "Вашы каналы:",
+	"filter_year":              "год %s",
+	"filter_spec":              "паток %s",
+	"inline_choose_group":      "Выберыце групу ў боце",
+	"inline_on_date":           "На %s",
+	"inline_week":              "На тыдзень",
+	"inline_today":             "Сёння",
+	"inline_tomorrow":          "Заўтра",
+	"inline_this_week":         "Гэты тыдзень",
+	"inline_no_lessons":        "Пар няма",
+	"inline_day_description":   "Пар: %d, першая ў %s",
+	"inline_week_description":  "Пар за тыдзень: %d",
+	"inline_nothing_found":     "Нічога не знойдзена",
+	"inline_nearest":           "Бліжэйшая: %s",
+	"ics_usage":                "Пазначце семестр: /ics 1, /ics 2 або /ics all",
+	"ics_choose_term":          "Выберыце семестр для экспарту ў каляндар:",
+	"ics_caption":              "Расклад групы %s: %s",
+	"pdf_usage":                "Пазначце семестр або перыяд: /pdf 1, /pdf 2, /pdf all або /pdf 01.09-31.10",
+	"pdf_choose_term":          "Выберыце семестр для друку. Для адвольнага перыяду выкарыстоўвайце каманду /pdf 01.09-31.10",
+	"pdf_title":                "Група %s — %s",
+	"pdf_title_subgroup":       "Група %s, падгрупа %s — %s",
+	"pdf_failed":               "Не атрымалася падрыхтаваць PDF, паспрабуйце пазней.",
+	"term_autumn":              "першы семестр",
+	"term_spring":              "другі семестр",
+	"term_all":                 "увесь навучальны год",
+	"term_not_found":           "Расклад на выбраны семестр не знойдзены.",
+	"period_not_found":         "Расклад на выбраны перыяд не знойдзены.",
+	"export_help":              "Выгрузка раскладу ў CSV або JSON:\n/export csv — ваша група за бягучы семестр\n/export json 01.09-31.10 — за перыяд\n/export csv all выкл:Іваноў — па выкладчыку\n/export csv аўд:1-305 — па аўдыторыі\n/export json група:22ИТ-1 — па іншай групе\n\nПалі: group, date (ГГГГ-ММ-ДД), weekday, start, end (ГГ:ХХ), subject, room, teacher, subgroup. Склад і назвы палёў не мяняюцца, новыя палі дадаюцца толькі ў канец.",
+	"export_nothing":           "Па гэтым запыце пар не знойдзена.",
+	"export_unavailable":       "Экспарт зараз недаступны.",
+	"export_caption":           "Пар у выгрузцы: %d",
+	"err_export":               "Памылка выгрузкі: %s",
+	"feed_unavailable":         "Падпіска на каляндар зараз недаступная.",
+	"feed_text":                "Дадайце гэтую спасылку ў каляндар як падпіску (Google Каляндар: «Дадаць па URL», iOS: «Дадаць каляндар-падпіску»):\n\n%s\n\nКаляндар абнаўляецца аўтаматычна і ўлічвае вашу асноўную групу і падгрупу. Не дзяліцеся спасылкай — па ёй даступны ваш расклад.",
+	"feed_revoked":             "Спасылка на каляндар адклікана, старыя падпіскі больш не абнаўляюцца.",
+	"err_feed_create":          "Памылка стварэння спасылкі: %s",
+	"err_feed_revoke":          "Памылка адклікання спасылкі: %s",
+	"ban_usage":                "Пазначце ID карыстальніка і, пры неабходнасці, прычыну, напрыклад: /ban 123456789 спам",
+	"tempban_usage":            "Пазначце ID карыстальніка, тэрмін (30m, 12h, 7d, 2w) і прычыну, напрыклад: /tempban 123456789 7d спам",
+	"unban_usage":              "Пазначце ID карыстальніка, напрыклад: /unban 123456789",
+	"err_moderation":           "Памылка мадэрацыі: %s",
+	"user_banned":              "Карыстальнік %d заблакаваны.",
+	"user_tempbanned":          "Карыстальнік %d заблакаваны да %s.",
+	"user_unbanned":            "Карыстальнік %d разблакаваны.",
+	"banned_notice":            "Ваш доступ да бота абмежаваны.",
+	"banned_until":             "Абмежаванне дзейнічае да %s.",
+	"banned_reason":            "Прычына: %s",
+	"inline_banned":            "Доступ да бота абмежаваны",
+	"modlog_header":            "Журнал мадэрацыі",
+	"modlog_empty":             "Журнал мадэрацыі пусты.",
+	"modlog_until":             "(да %s)",
+	"err_modlog":               "Памылка атрымання журнала: %s",
+	"label_group":              "Група:",
+	"btn_role":                 "🎓 Роля",
+	"btn_announce":             "📢 Аб'ява групе",
+	"btn_teacher_week":         "👨‍🏫 Мае заняткі",
+	"btn_admin":                "🛠 Адміністраванне",
+	"btn_request_headman":      "🙋 Я стараста сваёй групы",
+	"btn_approve":              "✅ Ухваліць",
+	"btn_reject":               "❌ Адхіліць",
+	"btn_role_requests":        "📝 Заяўкі на ролі",
+	"btn_modlog":               "📜 Журнал мадэрацыі",
+	"role_student":             "студэнт",
+	"role_headman":             "стараста",
+	"role_teacher":             "выкладчык",
+	"role_admin":               "адміністратар",
+	"role_required":            "Гэтая функцыя недаступная для вашай ролі.",
+	"role_current":             "Ваша роля: %s",
+	"role_headman_of":          "Група: %s",
+	"role_teacher_as":          "Выкладчык: %s",
+	"role_help":                "Стараста можа адпраўляць аб'явы сваёй групе, выкладчык — глядзець свой расклад.\n\nКаб стаць старастам, абярыце сваю групу і націсніце кнопку ніжэй або адпраўце /headman.\nКаб прывязацца да выкладчыка, адпраўце /teacher і сваё ПІБ, напрыклад: /teacher Іваноў Іван Іванавіч\n\nЗаяўку праверыць адміністратар.",
+	"role_already":             "У вас ужо ёсць гэтая роля.",
+	"teacher_usage":            "Пазначце ПІБ так, як яно пазначана ў раскладзе, напрыклад: /teacher Іваноў Іван Іванавіч",
+	"teacher_not_found":        "Выкладчык «%s» не знойдзены ў раскладзе.",
+	"teacher_ambiguous":        "Знойдзена некалькі выкладчыкаў, удакладніце ПІБ:\n%s",
+	"role_request_pending":     "Ваша папярэдняя заяўка яшчэ не разгледжана.",
+	"role_request_sent":        "Заяўка адпраўлена, адміністратар разгледзіць яе ў бліжэйшы час.",
+	"role_request_new":         "Новая заяўка на ролю:\n%s",
+	"err_role_request":         "Памылка адпраўкі заяўкі: %s",
+	"role_requests_header":     "Заяўкі на ролі",
+	"role_requests_empty":      "Новых заявак няма.",
+	"err_role_requests":        "Памылка атрымання заявак: %s",
+	"role_request_reviewed":    "Гэтая заяўка ўжо разгледжана.",
+	"err_role_review":          "Памылка: %s",
+	"role_review_done":         "Гатова",
+	"role_request_approved":    "Ваша заяўка на ролю «%s» ухвалена. Адкрыйце /start, каб убачыць новыя магчымасці.",
+	"role_request_rejected":    "Ваша заяўка на ролю «%s» адхілена.",
+	"announce_help":            "Аб'яву атрымаюць усе, у каго абрана група %s. Адпраўце яе камандай, напрыклад:\n/announce Заўтра першай пары не будзе",
+	"announce_sent":            "Аб'ява адпраўляецца, атрымальнікаў: %d.",
+	"announcement":             "📢 Аб'ява старасты групы %s:",
+	"err_announce":             "Памылка адпраўкі аб'явы: %s",
+	"stats_header":             "Статыстыка",
+	"stats_users":              "Карыстальнікаў: %d",
+	"stats_active":             "Актыўных: %d",
+	"stats_blocked":            "Заблакавалі бота: %d",
+	"stats_with_group":         "З абранай групай: %d",
+	"stats_headmen":            "Старастаў: %d",
+	"stats_teachers":           "Выкладчыкаў: %d",
+	"stats_banned":             "Заблакавана: %d",
+	"stats_role_requests":      "Заявак на ролі: %d",
+	"err_stats":                "Памылка атрымання статыстыкі: %s",
+	"broadcast_usage":          "Рассылка: у першым радку пасля каманды пазначце атрымальнікаў, з другога — тэкст паведамлення.\n\nАтрымальнікі: all — усе, active — толькі актыўныя за апошнія 30 дзён, year=22 — набор, spec=ИТ — паток, 22ИТ-1 — група. Умовы можна спалучаць, напрыклад:\n/broadcast active year=22 spec=ИТ\nЗаўтра бот будзе недаступны з 02:00 да 03:00.",
+	"err_broadcast":            "Памылка рассылкі: %s",
+	"broadcast_no_recipients":  "Па гэтых умовах атрымальнікаў няма.",
+	"broadcast_preview":        "<b>Папярэдні прагляд рассылкі</b>\nАтрымальнікі: %s\nУсяго: %d",
+	"btn_broadcast_send":       "📨 Адправіць",
+	"btn_broadcast_cancel":     "✖️ Адмяніць",
+	"broadcast_everyone":       "усе карыстальнікі",
+	"broadcast_active_only":    "толькі актыўныя",
+	"broadcast_started":        "Рассылка запушчана, атрымальнікаў: %d. Па завяршэнні прыйдзе справаздача.",
+	"broadcast_cancelled":      "Рассылка адменена.",
+	"broadcast_done":           "Рассылка #%d завершана.\nДастаўлена: %d\nЗаблакавалі бота: %d\nПамылак: %d",
+	"stats_dau":                "Актыўныя за суткі: %d",
+	"stats_wau":                "Актыўныя за тыдзень: %d",
+	"stats_mau":                "Актыўныя за месяц: %d",
+	"stats_groups":             "Карыстальнікі па групах",
+	"stats_features":           "Папулярныя функцыі за месяц",
+	"stats_errors":             "Памылак за суткі: %d",
+	"stats_latency":            "Час паказу раскладу за тыдзень (%d праглядаў): p50 %v, p95 %v, p99 %v",
+	"btn_report":               "⚠️ Паведаміць пра памылку",
+	"btn_cancel":               "✖️ Адмяніць",
+	"btn_close_report":         "✅ Закрыць",
+	"report_prompt":            "Апішыце адным паведамленнем, што не так у раскладзе групы %s на %s: напрыклад, няправільная аўдыторыя або пара. Паведамленне атрымаюць адміністратары.",
+	"report_cancelled":         "Паведамленне пра памылку адменена.",
+	"report_sent":              "Дзякуй! Паведамленне #%d адпраўлена адміністратарам.",
+	"err_report":               "Памылка адпраўкі паведамлення: %s",
+	"report_forward":           "⚠️ <b>Паведамленне пра памылку #%d</b>\nАд: %s\nГрупа: %s\nПрагляд: %s, %s",
+	"report_view_day":          "дзень",
+	"report_view_week":         "тыдзень",
+	"report_reply_hint":        "Адкажыце на гэтае паведамленне, каб напісаць карыстальніку, або адпраўце /reply %d тэкст.",
+	"reply_usage":              "Пазначце нумар паведамлення і тэкст адказу, напрыклад: /reply 12 Дзякуй, выправілі",
+	"report_not_found":         "Паведамленне #%d не знойдзена.",
+	"report_reply":             "💬 Адказ адміністратара на ваша паведамленне #%d:",
+	"report_reply_sent":        "Адказ на паведамленне #%d адпраўлены.",
+	"report_closed":            "Ваша паведамленне пра памылку #%d закрыта. Дзякуй за дапамогу!",
+	"report_closed_admin":      "Паведамленне #%d закрыта",
+	"doc_day":                  "Дзень",
+	"doc_time":                 "Час",
+	"doc_subject":              "Дысцыпліна",
+	"doc_room":                 "Аўдыторыя",
+	"doc_teacher":              "Выкладчык",
+	"doc_subgroup":             "Падгрупа",
+	"doc_group":                "Група",
+	"doc_updated_at":           "Даныя актуальныя на %s",
+	"doc_page":                 "Стар. %d",
+	"doc_week":                 "Тыдзень %s – %s",
+	"lesson_lecture":           "Лекцыя",
+	"lesson_practice":          "Практыка",
+	"lesson_lab":               "Лабараторная",
+	"lesson_exam":              "Залік / экзамен",
+	"lesson_other":             "Іншае",
+}
@@ -0,0 +1,316 @@
+package telegram_bot
+
+var messagesEN = map[string]string{
+	"btn_schedule":             "📆 Schedule",
+	"btn_settings":             "⚙️ Settings",
+	"btn_information":          "ℹ️ Information",
+	"btn_day":                  "📆 Day",
+	"btn_week":                 "📅 Week",
+	"btn_export_ics":           "🗓 Export to calendar",
+	"btn_export_pdf":           "🖨 Printable PDF",
+	"btn_back":                 "⬅️ Back",
+	"btn_choose_group":         "🔄 Choose group",
+	"btn_my_groups":            "📚 My groups",
+	"btn_subgroup":             "👥 Subgroup",
+	"btn_view_prefs":           "🎨 Appearance",
+	"btn_week_view":            "🖼 Week view",
+	"btn_ics_feed":             "🔗 Calendar subscription",
+	"btn_language":             "🌐 Language",
+	"btn_accept":               "Accept",
+	"btn_decline":              "Decline",
+	"btn_add_group":            "➕ Add group",
+	"btn_all_subgroups":        "All subgroups",
+	"btn_week_text":            "📝 As text",
+	"btn_week_image":           "🖼 As image",
+	"btn_pref_layout":          "Layout: %s",
+	"btn_pref_teachers":        "Teachers: %s",
+	"btn_pref_rooms":           "Rooms: %s",
+	"btn_pref_time":            "Time: %s",
+	"btn_pref_emoji":           "Emoji: %s",
+	"btn_reset":                "↩️ Reset",
+	"btn_feed_create":          "🔗 Create a new link",
+	"btn_feed_new":             "🔄 New link",
+	"btn_feed_revoke":          "🚫 Revoke link",
+	"btn_term_autumn":          "🍂 First term",
+	"btn_term_spring":          "🌱 Second term",
+	"btn_term_all":             "📚 Whole academic year",
+	"cmd_today":                "Today's schedule",
+	"cmd_tomorrow":             "Tomorrow's schedule",
+	"cmd_week":                 "This week's schedule",
+	"cmd_next":                 "Next class",
+	"cmd_date":                 "Schedule for a date, e.g. /date 15.10",
+	"cmd_find":                 "Find upcoming classes by subject, e.g. /find physics",
+	"cmd_ics":                  "Schedule as a calendar file (.ics)",
+	"cmd_pdf":                  "Printable schedule (PDF), e.g. /pdf 01.09-31.10",
+	"cmd_export":               "Export the schedule as CSV or JSON",
+	"cmd_feed":                 "Calendar subscription link",
+	"cmd_group":                "Choose a group, e.g. /group 22ИТ-1",
+	"cmd_channel":              "Post the schedule to a channel",
+	"cmd_bind":                 "Link this chat to a group, e.g. /bind 22ИТ-1",
+	"cmd_unbind":               "Unlink this chat from its group",
+	"cmd_autopost":             "Daily schedule posts: /autopost on or off",
+	"terms":                    "<b>Disclaimer</b>\n\nThe information provided by this bot is for reference only. We are not responsible for the accuracy, completeness or timeliness of the data. You use it at your own risk.\n\nPress the button below to accept the terms:",
+	"terms_accepted":           "Thank you for accepting the terms of service. Welcome!",
+	"terms_declined":           "You need to accept the terms of service to use this bot",
+	"terms_updated":            "The terms of service have been updated. Please review and accept them again.",
+	"err_accept_terms":         "Failed to save your consent: %s",
+	"inline_accept_terms":      "Accept the terms of service in the bot",
+	"main_menu":                "Main menu:",
+	"schedule_menu":            "Schedule menu:",
+	"settings_menu":            "Settings:",
+	"information":              "Information:",
+	"choose_language":          "Choose the interface language:",
+	"language_saved":           "Interface language: English.",
+	"group_header":             "Group %s",
+	"your_schedule":            "Your schedule",
+	"label_date":               "Date:",
+	"label_time":               "Time:",
+	"label_lesson":             "Class:",
+	"label_room":               "Room:",
+	"label_teacher":            "Teacher:",
+	"label_subgroup":           "Subgroup:",
+	"search_title":             "Upcoming classes matching",
+	"render_failed":            "Failed to display the schedule.",
+	"week_from":                "week of %s",
+	"week_schedule_from":       "schedule for the week of %s",
+	"schedule_changes":         "Schedule changes for group %s",
+	"no_lessons":               "No classes.",
+	"daily_no_lessons":         "Today's schedule has changed: no classes.",
+	"daily_post_disabled":      "⚠️ Daily schedule posts in chat %d (group %s) were turned off: %s",
+	"no_group_selected":        "You haven't chosen a group to view the schedule for.",
+	"no_group":                 "You haven't chosen a group. Set it with /group, e.g. /group 22ИТ-1",
+	"chat_not_bound":           "This chat is not linked to a group. A chat admin can link it with /bind, e.g. /bind 22ИТ-1",
+	"no_day_schedule":          "No schedule found for %s",
+	"no_week_schedule":         "No schedule found for this week.",
+	"no_upcoming":              "No upcoming classes found.",
+	"next_lesson":              "Next class:",
+	"date_usage":               "Enter a date as DD.MM, e.g. /date 15.10",
+	"find_usage":               "Enter a subject name, e.g. /find calculus",
+	"query_not_understood":     "Sorry, I didn't understand that. Try e.g. «tomorrow», «fri», «next tuesday», «15.10», «week» or «when physics».",
+	"subject_not_found":        "No class «%s» found in the upcoming schedule.",
+	"err_generic":              "Error: %s",
+	"err_schedule":             "Failed to get the schedule: %s",
+	"err_groups":               "Failed to get groups: %s",
+	"err_subgroups":            "Failed to get subgroups: %s",
+	"err_save_group":           "Failed to save the group: %s",
+	"err_save_subgroup":        "Failed to save the subgroup: %s",
+	"err_save_setting":         "Failed to save the setting: %s",
+	"err_spec_data":            "Error: invalid specialization data.",
+	"choose_year":              "Choose your year of admission:",
+	"choose_spec":              "Choose your stream:",
+	"choose_group":             "Choose your group:",
+	"choose_group_first":       "Choose a group first.",
+	"choose_group_in_settings": "Choose a group in the settings first.",
+	"group_selected":           "Your group has been set: %s",
+	"group_not_found":          "Group %s not found.",
+	"group_usage":              "Enter a group, e.g. /group 22ИТ-1",
+	"group_in_chat":            "In a group chat the group is set by an admin with /bind, e.g. /bind 22ИТ-1",
+	"no_saved_groups":          "You have no saved groups yet.",
+	"my_groups":                "Your groups (⭐ — primary).\nTap a group to make it primary:",
+	"group_not_saved":          "This group is not saved.",
+	"primary_group":            "Primary group: %s",
+	"cannot_remove_last":       "You can't remove your only group.",
+	"group_removed":            "Group %s removed. Primary group: %s",
+	"no_subgroups":             "Group %s has no subgroup classes.",
+	"subgroup_all":             "all",
+	"choose_subgroup":          "Choose your subgroup (current: %s):",
+	"subgroup_cleared":         "Classes of all subgroups are shown now.",
+	"subgroup_selected":        "Your subgroup has been set: %s",
+	"view_prefs":               "Schedule appearance. By default the day view is detailed and the week view is compact, with shortened teacher names.",
+	"pref_default":             "default",
+	"pref_compact":             "compact",
+	"pref_detailed":            "detailed",
+	"pref_full":                "full",
+	"pref_short":               "short",
+	"pref_start":               "start time",
+	"pref_range":               "start and end",
+	"pref_show":                "show",
+	"pref_hide":                "hide",
+	"pref_on":                  "on",
+	"pref_off":                 "off",
+	"choose_week_view":         "How should the week schedule be shown?",
+	"week_view_text":           "The week schedule will be shown as text.",
+	"week_view_image":          "The week schedule will be shown as an image.",
+	"private_only_menu":        "This menu is only available in a private chat with the bot.",
+	"private_only_command":     "This command is only available in a private chat with the bot. In this chat use /today, /week or /bind.",
+	"chat_welcome":             "Hi! A chat admin can link this chat to a study group with /bind, e.g. /bind 22ИТ-1. After that /today, /tomorrow, /week and /next will work here.",
+	"bind_group_only":          "/bind only works in group chats. In a private chat use /group.",
+	"bind_admin_only":          "Only a chat admin can link the chat to a group.",
+	"bind_usage":               "Enter a group, e.g. /bind 22ИТ-1",
+	"chat_bound":               "The chat is linked to group %s. /today, /tomorrow, /week and /next work here now, and every morning at %d:00 the bot will post and pin the day's schedule (turn off: /autopost off).",
+	"err_rights":               "Failed to check permissions: %s",
+	"unbind_group_only":        "/unbind only works in group chats.",
+	"unbind_admin_only":        "Only a chat admin can unlink the chat from its group.",
+	"err_unbind":               "Failed to unlink the chat: %s",
+	"chat_unbound":             "The chat is unlinked from its group.",
+	"autopost_group_only":      "/autopost only works in group chats.",
+	"autopost_admin_only":      "Only a chat admin can configure posting.",
+	"autopost_usage":           "Enter on or off, e.g. /autopost off",
+	"autopost_on":              "Daily schedule posts are on (at %d:00).",
+	"autopost_off":             "Daily schedule posts are off.",
+	"channel_usage":            "Posting the schedule to a channel:\n\n/channel add @channel 22ИТ-1 22ИТ-2 — post the schedule of the listed groups\n/channel add @channel year=22 spec=ИТ — post the schedule of all 2022 ИТ groups\n/channel remove @channel — stop posting\n/channel list — your channels\n\nThe bot must be a channel admin allowed to post messages.",
+	"channel_not_channel":      "%s is not a channel",
+	"channel_err_user_rights":  "failed to check your permissions in the channel: %v",
+	"channel_not_admin":        "you are not an admin of channel %s",
+	"channel_err_bot_rights":   "failed to check the bot's permissions in the channel: %v",
+	"channel_bot_not_admin":    "the bot must be an admin of channel %s allowed to post messages",
+	"channel_not_found":        "Channel %s not found: %s",
+	"channel_added":            "Channel %s connected. Matching groups: %d. The week schedule will be posted on Sundays at %d:00, and changes after every update.",
+	"channel_not_added":        "Channel %s is not connected.",
+	"channel_removed":          "Posting to channel %s is off.",
+	"err_save_channel":         "Failed to save the channel: %s",
+	"err_delete_channel":       "Failed to remove the channel: %s",
+	"err_channels":             "Failed to get channels: %s",
+	"no_channels":              "You haven't connected any channels yet.",
+	"your_channels":            "Your channels:",
+	"filter_year":              "year %s",
+	"filter_spec":              "stream %s",
+	"inline_choose_group":      "Choose a group in the bot",
+	"inline_on_date":           "On %s",
+	"inline_week":              "Week",
+	"inline_today":             "Today",
+	"inline_tomorrow":          "Tomorrow",
+	"inline_this_week":         "This week",
+	"inline_no_lessons":        "No classes",
+	"inline_day_description":   "Classes: %d, first at %s",
+	"inline_week_description":  "Classes this week: %d",
+	"inline_nothing_found":     "Nothing found",
+	"inline_nearest":           "Nearest: %s",
+	"ics_usage":                "Enter a term: /ics 1, /ics 2 or /ics all",
+	"ics_choose_term":          "Choose a term to export to your calendar:",
+	"ics_caption":              "Schedule of group %s: %s",
+	"pdf_usage":                "Enter a term or period: /pdf 1, /pdf 2, /pdf all or /pdf 01.09-31.10",
+	"pdf_choose_term":          "Choose a term to print. For a custom period use /pdf 01.09-31.10",
+	"pdf_title":                "Group %s — %s",
+	"pdf_title_subgroup":       "Group %s, subgroup %s — %s",
+	"pdf_failed":               "Failed to prepare the PDF, please try again later.",
+	"term_autumn":              "first term",
+	"term_spring":              "second term",
+	"term_all":                 "whole academic year",
+	"term_not_found":           "No schedule found for the selected term.",
+	"period_not_found":         "No schedule found for the selected period.",
+	"export_help":              "Export the schedule as CSV or JSON:\n/export csv — your group for the current term\n/export json 01.09-31.10 — for a period\n/export csv all teacher:Ivanov — by teacher\n/export csv room:1-305 — by room\n/export json group:22ИТ-1 — another group\n\nFields: group, date (YYYY-MM-DD), weekday, start, end (HH:MM), subject, room, teacher, subgroup. Field names and order never change; new fields are only appended.",
+	"export_nothing":           "No classes match this query.",
+	"export_unavailable":       "Export is not available right now.",
+	"export_caption":           "Classes exported: %d",
+	"err_export":               "Export failed: %s",
+	"feed_unavailable":         "Calendar subscription is currently unavailable.",
+	"feed_text":                "Add this link to your calendar as a subscription (Google Calendar: «From URL», iOS: «Add Subscription Calendar»):\n\n%s\n\nThe calendar updates automatically and follows your primary group and subgroup. Don't share the link — it gives access to your schedule.",
+	"feed_revoked":             "The calendar link has been revoked; old subscriptions no longer update.",
+	"err_feed_create":          "Failed to create the link: %s",
+	"err_feed_revoke":          "Failed to revoke the link: %s",
+	"ban_usage":                "Enter a user ID and optionally a reason, e.g. /ban 123456789 spam",
+	"tempban_usage":            "Enter a user ID, a duration (30m, 12h, 7d, 2w) and a reason, e.g. /tempban 123456789 7d spam",
+	"unban_usage":              "Enter a user ID, e.g. /unban 123456789",
+	"err_moderation":           "Moderation failed: %s",
+	"user_banned":              "User %d has been banned.",
+	"user_tempbanned":          "User %d has been banned until %s.",
+	"user_unbanned":            "User %d has been unbanned.",
+	"banned_notice":            "Your access to the bot is restricted.",
+	"banned_until":             "The restriction lasts until %s.",
+	"banned_reason":            "Reason: %s",
+	"inline_banned":            "Access to the bot is restricted",
+	"modlog_header":            "Moderation log",
+	"modlog_empty":             "The moderation log is empty.",
+	"modlog_until":             "(until %s)",
+	"err_modlog":               "Failed to load the log: %s",
+	"label_group":              "Group:",
+	"btn_role":                 "🎓 Role",
+	"btn_announce":             "📢 Group announcement",
+	"btn_teacher_week":         "👨‍🏫 My classes",
+	"btn_admin":                "🛠 Administration",
+	"btn_request_headman":      "🙋 I am my group's headman",
+	"btn_approve":              "✅ Approve",
+	"btn_reject":               "❌ Reject",
+	"btn_role_requests":        "📝 Role requests",
+	"btn_modlog":               "📜 Moderation log",
+	"role_student":             "student",
+	"role_headman":             "headman",
+	"role_teacher":             "teacher",
+	"role_admin":               "administrator",
+	"role_required":            "This feature is not available for your role.",
+	"role_current":             "Your role: %s",
+	"role_headman_of":          "Group: %s",
+	"role_teacher_as":          "Teacher: %s",
+	"role_help":                "A headman can send announcements to their group, a teacher can view their own schedule.\n\nTo become a headman, choose your group and press the button below or send /headman.\nTo link your account to a teacher, send /teacher with your full name, e.g. /teacher Ivanov Ivan Ivanovich\n\nAn administrator will review the request.",
+	"role_already":             "You already have this role.",
+	"teacher_usage":            "Enter your full name as it appears in the schedule, e.g. /teacher Ivanov Ivan Ivanovich",
+	"teacher_not_found":        "Teacher \"%s\" was not found in the schedule.",
+	"teacher_ambiguous":        "Several teachers match, please be more specific:\n%s",
+	"role_request_pending":     "Your previous request has not been reviewed yet.",
+	"role_request_sent":        "Request sent; an administrator will review it soon.",
+	"role_request_new":         "New role request:\n%s",
+	"err_role_request":         "Failed to send the request: %s",
+	"role_requests_header":     "Role requests",
+	"role_requests_empty":      "No new requests.",
+	"err_role_requests":        "Failed to load requests: %s",
+	"role_request_reviewed":    "This request has already been reviewed.",
+	"err_role_review":          "Error: %s",
+	"role_review_done":         "Done",
+	"role_request_approved":    "Your request for the %s role has been approved. Open /start to see the new features.",
+	"role_request_rejected":    "Your request for the %s role has been rejected.",
+	"announce_help":            "The announcement will reach everyone who has chosen group %s. Send it with the command, e.g.:\n/announce There is no first class tomorrow",
+	"announce_sent":            "Sending the announcement to %d recipients.",
+	"announcement":             "📢 Announcement from the headman of group %s:",
+	"err_announce":             "Failed to send the announcement: %s",
+	"stats_header":             "Statistics",
+	"stats_users":              "Users: %d",
+	"stats_active":             "Active: %d",
+	"stats_blocked":            "Blocked the bot: %d",
+	"stats_with_group":         "With a group: %d",
+	"stats_headmen":            "Headmen: %d",
+	"stats_teachers":           "Teachers: %d",
+	"stats_banned":             "Banned: %d",
+	"stats_role_requests":      "Role requests: %d",
+	"err_stats":                "Failed to load statistics: %s",
+	"broadcast_usage":          "Broadcast: put the recipients on the first line after the command and the message text on the following lines.\n\nRecipients: all — everyone, active — only users active in the last 30 days, year=22 — admission year, spec=ИТ — specialization, 22ИТ-1 — group. Filters can be combined, e.g.:\n/broadcast active year=22 spec=ИТ\nThe bot will be unavailable tomorrow from 02:00 to 03:00.",
+	"err_broadcast":            "Broadcast failed: %s",
+	"broadcast_no_recipients":  "No recipients match these filters.",
+	"broadcast_preview":        "<b>Broadcast preview</b>\nRecipients: %s\nTotal: %d",
+	"btn_broadcast_send":       "📨 Send",
+	"btn_broadcast_cancel":     "✖️ Cancel",
+	"broadcast_everyone":       "all users",
+	"broadcast_active_only":    "active only",
+	"broadcast_started":        "Broadcast started, recipients: %d. You will get a report when it finishes.",
+	"broadcast_cancelled":      "Broadcast cancelled.",
+	"broadcast_done":           "Broadcast #%d finished.\nDelivered: %d\nBlocked the bot: %d\nErrors: %d",
+	"stats_dau":                "Active today: %d",
+	"stats_wau":                "Active this week: %d",
+	"stats_mau":                "Active this month: %d",
+	"stats_groups":             "Users per group",
+	"stats_features":           "Most used features this month",
+	"stats_errors":             "Errors today: %d",
+	"stats_latency":            "Schedule view latency this week (%d views): p50 %v, p95 %v, p99 %v",
+	"btn_report":               "⚠️ Report a mistake",
+	"btn_cancel":               "✖️ Cancel",
+	"btn_close_report":         "✅ Close",
+	"report_prompt":            "Describe in one message what is wrong in the schedule of group %s for %s, e.g. a wrong room or class. Administrators will receive it.",
+	"report_cancelled":         "Report cancelled.",
+	"report_sent":              "Thank you! Report #%d has been sent to the administrators.",
+	"err_report":               "Failed to send the report: %s",
+	"report_forward":           "⚠️ <b>Report #%d</b>\nFrom: %s\nGroup: %s\nView: %s, %s",
+	"report_view_day":          "day",
+	"report_view_week":         "week",
+	"report_reply_hint":        "Reply to this message to answer the user, or send /reply %d text.",
+	"reply_usage":              "Enter the report number and the reply, e.g. /reply 12 Thanks, fixed",
+	"report_not_found":         "Report #%d was not found.",
+	"report_reply":             "💬 An administrator replied to your report #%d:",
+	"report_reply_sent":        "The reply to report #%d has been sent.",
+	"report_closed":            "Your report #%d has been closed. Thanks for your help!",
+	"report_closed_admin":      "Report #%d closed",
+	"doc_day":                  "Day",
+	"doc_time":                 "Time",
+	"doc_subject":              "Subject",
+	"doc_room":                 "Room",
+	"doc_teacher":              "Teacher",
+	"doc_subgroup":             "Subgroup",
+	"doc_group":                "Group",
+	"doc_updated_at":           "Data as of %s",
+	"doc_page":                 "Page %d",
+	"doc_week":                 "Week %s – %s",
+	"lesson_lecture":           "Lecture",
+	"lesson_practice":          "Practice",
+	"lesson_lab":               "Lab",
+	"lesson_exam":              "Test / exam",
+	"lesson_other":             "Other",
+}
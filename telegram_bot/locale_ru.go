@@ -0,0 +1,316 @@
+package telegram_bot
+
+var messagesRU = map[string]string{
+	"btn_schedule":             "📆 Расписание",
+	"btn_settings":             "⚙️ Настройки",
+	"btn_information":          "ℹ️ Информация",
+	"btn_day":                  "📆 На день",
+	"btn_week":                 "📅 На неделю",
+	"btn_export_ics":           "🗓 Экспорт в календарь",
+	"btn_export_pdf":           "🖨 PDF для печати",
+	"btn_back":                 "⬅️ Назад",
+	"btn_choose_group":         "🔄 Выбрать группу",
+	"btn_my_groups":            "📚 Мои группы",
+	"btn_subgroup":             "👥 Подгруппа",
+	"btn_view_prefs":           "🎨 Оформление",
+	"btn_week_view":            "🖼 Вид недели",
+	"btn_ics_feed":             "🔗 Подписка на календарь",
+	"btn_language":             "🌐 Язык",
+	"btn_accept":               "Принять",
+	"btn_decline":              "Отказаться",
+	"btn_add_group":            "➕ Добавить группу",
+	"btn_all_subgroups":        "Все подгруппы",
+	"btn_week_text":            "📝 Текстом",
+	"btn_week_image":           "🖼 Картинкой",
+	"btn_pref_layout":          "Формат: %s",
+	"btn_pref_teachers":        "Преподаватели: %s",
+	"btn_pref_rooms":           "Аудитории: %s",
+	"btn_pref_time":            "Время: %s",
+	"btn_pref_emoji":           "Эмодзи: %s",
+	"btn_reset":                "↩️ Сбросить",
+	"btn_feed_create":          "🔗 Создать новую ссылку",
+	"btn_feed_new":             "🔄 Новая ссылка",
+	"btn_feed_revoke":          "🚫 Отозвать ссылку",
+	"btn_term_autumn":          "🍂 Первый семестр",
+	"btn_term_spring":          "🌱 Второй семестр",
+	"btn_term_all":             "📚 Весь учебный год",
+	"cmd_today":                "Расписание на сегодня",
+	"cmd_tomorrow":             "Расписание на завтра",
+	"cmd_week":                 "Расписание на неделю",
+	"cmd_next":                 "Следующая пара",
+	"cmd_date":                 "Расписание на дату, например /date 15.10",
+	"cmd_find":                 "Найти ближайшие пары по предмету, например /find физика",
+	"cmd_ics":                  "Расписание в формате календаря (.ics)",
+	"cmd_pdf":                  "Расписание для печати (PDF), например /pdf 01.09-31.10",
+	"cmd_export":               "Выгрузка расписания в CSV или JSON",
+	"cmd_feed":                 "Ссылка для подписки на календарь",
+	"cmd_group":                "Выбрать группу, например /group 22ИТ-1",
+	"cmd_channel":              "Публикация расписания в канал",
+	"cmd_bind":                 "Привязать чат к группе, например /bind 22ИТ-1",
+	"cmd_unbind":               "Отвязать чат от группы",
+	"cmd_autopost":             "Ежедневная публикация расписания: /autopost on или off",
+	"terms":                    "<b>Отказ от ответственности</b>\n\nИнформация, предоставляемая ботом, носит справочный характер. Мы не несем ответственности за точность, полноту или актуальность данных. Использование информации осуществляется на ваш собственный риск.\n\nНажмите кнопку ниже, чтобы принять правила:",
+	"terms_accepted":           "Благодарим вас за принятие условий предоставления услуг. Добро пожаловать в бот!",
+	"terms_declined":           "Чтобы использовать этого бота, вам необходимо принять условия предоставления услуг",
+	"terms_updated":            "Условия использования обновились. Пожалуйста, ознакомьтесь с ними и примите их снова.",
+	"err_accept_terms":         "Ошибка сохранения согласия: %s",
+	"inline_accept_terms":      "Примите условия использования в боте",
+	"main_menu":                "Главное меню:",
+	"schedule_menu":            "Меню расписания:",
+	"settings_menu":            "Настройки:",
+	"information":              "Информация:",
+	"choose_language":          "Выберите язык интерфейса:",
+	"language_saved":           "Язык интерфейса: русский.",
+	"group_header":             "Группа %s",
+	"your_schedule":            "Ваше расписание",
+	"label_date":               "Дата:",
+	"label_time":               "Время:",
+	"label_lesson":             "Пара:",
+	"label_room":               "Аудит.:",
+	"label_teacher":            "Препод.:",
+	"label_subgroup":           "Подгруппа:",
+	"search_title":             "Ближайшие пары по запросу",
+	"render_failed":            "Не удалось отобразить расписание.",
+	"week_from":                "неделя с %s",
+	"week_schedule_from":       "расписание на неделю с %s",
+	"schedule_changes":         "Изменения в расписании группы %s",
+	"no_lessons":               "Пар нет.",
+	"daily_no_lessons":         "Расписание на сегодня изменилось: пар нет.",
+	"daily_post_disabled":      "⚠️ Автопубликация расписания в чате %d (группа %s) отключена: %s",
+	"no_group_selected":        "Вы не выбрали группу для просмотра расписания.",
+	"no_group":                 "Вы не выбрали группу. Укажите её командой /group, например: /group 22ИТ-1",
+	"chat_not_bound":           "Этот чат не привязан к группе. Администратор чата может привязать его командой /bind, например: /bind 22ИТ-1",
+	"no_day_schedule":          "Расписание не найдено на дату %s",
+	"no_week_schedule":         "Расписание не найдено на эту неделю.",
+	"no_upcoming":              "Предстоящих пар не найдено.",
+	"next_lesson":              "Следующая пара:",
+	"date_usage":               "Укажите дату в формате ДД.ММ, например: /date 15.10",
+	"find_usage":               "Укажите название предмета, например: /find высшая математика",
+	"query_not_understood":     "Не удалось распознать запрос. Попробуйте, например: «завтра», «пт», «следующий вторник», «15.10», «неделя» или «когда физика».",
+	"subject_not_found":        "Пара «%s» в ближайшем расписании не найдена.",
+	"err_generic":              "Ошибка: %s",
+	"err_schedule":             "Ошибка получения расписания: %s",
+	"err_groups":               "Ошибка получения групп: %s",
+	"err_subgroups":            "Ошибка получения подгрупп: %s",
+	"err_save_group":           "Ошибка сохранения группы: %s",
+	"err_save_subgroup":        "Ошибка сохранения подгруппы: %s",
+	"err_save_setting":         "Ошибка сохранения настройки: %s",
+	"err_spec_data":            "Ошибка: некорректные данные для специальности.",
+	"choose_year":              "Выберите год поступления:",
+	"choose_spec":              "Выберите поток:",
+	"choose_group":             "Выберите группу:",
+	"choose_group_first":       "Сначала выберите группу.",
+	"choose_group_in_settings": "Сначала выберите группу в настройках.",
+	"group_selected":           "Ваша группа была успешно выбрана: %s",
+	"group_not_found":          "Группа %s не найдена.",
+	"group_usage":              "Укажите группу, например: /group 22ИТ-1",
+	"group_in_chat":            "В групповом чате группа задаётся администратором командой /bind, например: /bind 22ИТ-1",
+	"no_saved_groups":          "У вас пока нет сохранённых групп.",
+	"my_groups":                "Ваши группы (⭐ — основная).\nНажмите на группу, чтобы сделать её основной:",
+	"group_not_saved":          "Эта группа не сохранена.",
+	"primary_group":            "Основная группа: %s",
+	"cannot_remove_last":       "Нельзя удалить единственную группу.",
+	"group_removed":            "Группа %s удалена. Основная группа: %s",
+	"no_subgroups":             "У группы %s нет занятий по подгруппам.",
+	"subgroup_all":             "все",
+	"choose_subgroup":          "Выберите подгруппу (сейчас: %s):",
+	"subgroup_cleared":         "Теперь показываются пары всех подгрупп.",
+	"subgroup_selected":        "Ваша подгруппа была успешно выбрана: %s",
+	"view_prefs":               "Оформление расписания. По умолчанию расписание на день показывается подробно, а на неделю — компактно, с сокращёнными именами преподавателей.",
+	"pref_default":             "по умолчанию",
+	"pref_compact":             "компактный",
+	"pref_detailed":            "подробный",
+	"pref_full":                "полностью",
+	"pref_short":               "сокращённо",
+	"pref_start":               "начало пары",
+	"pref_range":               "начало и конец",
+	"pref_show":                "показывать",
+	"pref_hide":                "скрывать",
+	"pref_on":                  "вкл.",
+	"pref_off":                 "выкл.",
+	"choose_week_view":         "Как показывать расписание на неделю?",
+	"week_view_text":           "Расписание на неделю будет показываться текстом.",
+	"week_view_image":          "Расписание на неделю будет показываться картинкой.",
+	"private_only_menu":        "Это меню доступно только в личных сообщениях с ботом.",
+	"private_only_command":     "Эта команда доступна только в личных сообщениях с ботом. В чате используйте /today, /week или /bind.",
+	"chat_welcome":             "Привет! Администратор чата может привязать его к учебной группе командой /bind, например: /bind 22ИТ-1. После этого в чате будут работать /today, /tomorrow, /week и /next.",
+	"bind_group_only":          "Команда /bind работает только в групповых чатах. В личных сообщениях используйте /group.",
+	"bind_admin_only":          "Привязать чат к группе может только администратор чата.",
+	"bind_usage":               "Укажите группу, например: /bind 22ИТ-1",
+	"chat_bound":               "Чат привязан к группе %s. Теперь здесь работают /today, /tomorrow, /week и /next, а каждое утро в %d:00 бот будет публиковать и закреплять расписание на день (отключить: /autopost off).",
+	"err_rights":               "Ошибка проверки прав: %s",
+	"unbind_group_only":        "Команда /unbind работает только в групповых чатах.",
+	"unbind_admin_only":        "Отвязать чат от группы может только администратор чата.",
+	"err_unbind":               "Ошибка отвязки чата: %s",
+	"chat_unbound":             "Чат отвязан от группы.",
+	"autopost_group_only":      "Команда /autopost работает только в групповых чатах.",
+	"autopost_admin_only":      "Настраивать публикацию может только администратор чата.",
+	"autopost_usage":           "Укажите on или off, например: /autopost off",
+	"autopost_on":              "Ежедневная публикация расписания включена (в %d:00).",
+	"autopost_off":             "Ежедневная публикация расписания отключена.",
+	"channel_usage":            "Публикация расписания в канал:\n\n/channel add @канал 22ИТ-1 22ИТ-2 — публиковать расписание выбранных групп\n/channel add @канал year=22 spec=ИТ — публиковать расписание всех групп набора 22 года потока ИТ\n/channel remove @канал — прекратить публикацию\n/channel list — ваши каналы\n\nБот должен быть администратором канала с правом публикации сообщений.",
+	"channel_not_channel":      "%s не является каналом",
+	"channel_err_user_rights":  "не удалось проверить ваши права в канале: %v",
+	"channel_not_admin":        "вы не являетесь администратором канала %s",
+	"channel_err_bot_rights":   "не удалось проверить права бота в канале: %v",
+	"channel_bot_not_admin":    "бот должен быть администратором канала %s с правом публикации сообщений",
+	"channel_not_found":        "Канал %s не найден: %s",
+	"channel_added":            "Канал %s подключён. Групп под фильтр: %d. Расписание на неделю будет публиковаться по воскресеньям в %d:00, а изменения — после каждого обновления.",
+	"channel_not_added":        "Канал %s не подключён.",
+	"channel_removed":          "Публикация в канал %s отключена.",
+	"err_save_channel":         "Ошибка сохранения канала: %s",
+	"err_delete_channel":       "Ошибка удаления канала: %s",
+	"err_channels":             "Ошибка получения каналов: %s",
+	"no_channels":              "Вы пока не подключили ни одного канала.",
+	"your_channels":            "Ваши каналы:",
+	"filter_year":              "год %s",
+	"filter_spec":              "поток %s",
+	"inline_choose_group":      "Выберите группу в боте",
+	"inline_on_date":           "На %s",
+	"inline_week":              "На неделю",
+	"inline_today":             "Сегодня",
+	"inline_tomorrow":          "Завтра",
+	"inline_this_week":         "Эта неделя",
+	"inline_no_lessons":        "Пар нет",
+	"inline_day_description":   "Пар: %d, первая в %s",
+	"inline_week_description":  "Пар за неделю: %d",
+	"inline_nothing_found":     "Ничего не найдено",
+	"inline_nearest":           "Ближайшая: %s",
+	"ics_usage":                "Укажите семестр: /ics 1, /ics 2 или /ics all",
+	"ics_choose_term":          "Выберите семестр для экспорта в календарь:",
+	"ics_caption":              "Расписание группы %s: %s",
+	"pdf_usage":                "Укажите семестр или период: /pdf 1, /pdf 2, /pdf all или /pdf 01.09-31.10",
+	"pdf_choose_term":          "Выберите семестр для печати. Для произвольного периода используйте команду /pdf 01.09-31.10",
+	"pdf_title":                "Группа %s — %s",
+	"pdf_title_subgroup":       "Группа %s, подгруппа %s — %s",
+	"pdf_failed":               "Не удалось подготовить PDF, попробуйте позже.",
+	"term_autumn":              "первый семестр",
+	"term_spring":              "второй семестр",
+	"term_all":                 "весь учебный год",
+	"term_not_found":           "Расписание на выбранный семестр не найдено.",
+	"period_not_found":         "Расписание на выбранный период не найдено.",
+	"export_help":              "Выгрузка расписания в CSV или JSON:\n/export csv — ваша группа за текущий семестр\n/export json 01.09-31.10 — за период\n/export csv all преп:Иванов — по преподавателю\n/export csv ауд:1-305 — по аудитории\n/export json группа:22ИТ-1 — по другой группе\n\nПоля: group, date (ГГГГ-ММ-ДД), weekday, start, end (ЧЧ:ММ), subject, room, teacher, subgroup. Состав и названия полей не меняются, новые поля добавляются только в конец.",
+	"export_nothing":           "По этому запросу пар не найдено.",
+	"export_unavailable":       "Экспорт сейчас недоступен.",
+	"export_caption":           "Пар в выгрузке: %d",
+	"err_export":               "Ошибка выгрузки: %s",
+	"feed_unavailable":         "Подписка на календарь сейчас недоступна.",
+	"feed_text":                "Добавьте эту ссылку в календарь как подписку (Google Календарь: «Добавить по URL», iOS: «Добавить подписной календарь»):\n\n%s\n\nКалендарь обновляется автоматически и учитывает вашу основную группу и подгруппу. Не делитесь ссылкой — по ней доступно ваше расписание.",
+	"feed_revoked":             "Ссылка на календарь отозвана, старые подписки больше не обновляются.",
+	"err_feed_create":          "Ошибка создания ссылки: %s",
+	"err_feed_revoke":          "Ошибка отзыва ссылки: %s",
+	"ban_usage":                "Укажите ID пользователя и, при необходимости, причину, например: /ban 123456789 спам",
+	"tempban_usage":            "Укажите ID пользователя, срок (30m, 12h, 7d, 2w) и причину, например: /tempban 123456789 7d спам",
+	"unban_usage":              "Укажите ID пользователя, например: /unban 123456789",
+	"err_moderation":           "Ошибка модерации: %s",
+	"user_banned":              "Пользователь %d заблокирован.",
+	"user_tempbanned":          "Пользователь %d заблокирован до %s.",
+	"user_unbanned":            "Пользователь %d разблокирован.",
+	"banned_notice":            "Ваш доступ к боту ограничен.",
+	"banned_until":             "Ограничение действует до %s.",
+	"banned_reason":            "Причина: %s",
+	"inline_banned":            "Доступ к боту ограничен",
+	"modlog_header":            "Журнал модерации",
+	"modlog_empty":             "Журнал модерации пуст.",
+	"modlog_until":             "(до %s)",
+	"err_modlog":               "Ошибка получения журнала: %s",
+	"label_group":              "Группа:",
+	"btn_role":                 "🎓 Роль",
+	"btn_announce":             "📢 Объявление группе",
+	"btn_teacher_week":         "👨‍🏫 Мои занятия",
+	"btn_admin":                "🛠 Администрирование",
+	"btn_request_headman":      "🙋 Я староста своей группы",
+	"btn_approve":              "✅ Одобрить",
+	"btn_reject":               "❌ Отклонить",
+	"btn_role_requests":        "📝 Заявки на роли",
+	"btn_modlog":               "📜 Журнал модерации",
+	"role_student":             "студент",
+	"role_headman":             "староста",
+	"role_teacher":             "преподаватель",
+	"role_admin":               "администратор",
+	"role_required":            "Эта функция недоступна для вашей роли.",
+	"role_current":             "Ваша роль: %s",
+	"role_headman_of":          "Группа: %s",
+	"role_teacher_as":          "Преподаватель: %s",
+	"role_help":                "Староста может отправлять объявления своей группе, преподаватель — смотреть своё расписание.\n\nЧтобы стать старостой, выберите свою группу и нажмите кнопку ниже или отправьте /headman.\nЧтобы привязаться к преподавателю, отправьте /teacher и своё ФИО, например: /teacher Иванов Иван Иванович\n\nЗаявку проверит администратор.",
+	"role_already":             "У вас уже есть эта роль.",
+	"teacher_usage":            "Укажите ФИО так, как оно указано в расписании, например: /teacher Иванов Иван Иванович",
+	"teacher_not_found":        "Преподаватель «%s» не найден в расписании.",
+	"teacher_ambiguous":        "Найдено несколько преподавателей, уточните ФИО:\n%s",
+	"role_request_pending":     "Ваша предыдущая заявка ещё не рассмотрена.",
+	"role_request_sent":        "Заявка отправлена, администратор рассмотрит её в ближайшее время.",
+	"role_request_new":         "Новая заявка на роль:\n%s",
+	"err_role_request":         "Ошибка отправки заявки: %s",
+	"role_requests_header":     "Заявки на роли",
+	"role_requests_empty":      "Новых заявок нет.",
+	"err_role_requests":        "Ошибка получения заявок: %s",
+	"role_request_reviewed":    "Эта заявка уже рассмотрена.",
+	"err_role_review":          "Ошибка: %s",
+	"role_review_done":         "Готово",
+	"role_request_approved":    "Ваша заявка на роль «%s» одобрена. Откройте /start, чтобы увидеть новые возможности.",
+	"role_request_rejected":    "Ваша заявка на роль «%s» отклонена.",
+	"announce_help":            "Объявление получат все, у кого выбрана группа %s. Отправьте его командой, например:\n/announce Завтра первой пары не будет",
+	"announce_sent":            "Объявление отправляется, получателей: %d.",
+	"announcement":             "📢 Объявление старосты группы %s:",
+	"err_announce":             "Ошибка отправки объявления: %s",
+	"stats_header":             "Статистика",
+	"stats_users":              "Пользователей: %d",
+	"stats_active":             "Активных: %d",
+	"stats_blocked":            "Заблокировали бота: %d",
+	"stats_with_group":         "С выбранной группой: %d",
+	"stats_headmen":            "Старост: %d",
+	"stats_teachers":           "Преподавателей: %d",
+	"stats_banned":             "Заблокировано: %d",
+	"stats_role_requests":      "Заявок на роли: %d",
+	"err_stats":                "Ошибка получения статистики: %s",
+	"broadcast_usage":          "Рассылка: в первой строке после команды укажите получателей, со второй — текст сообщения.\n\nПолучатели: all — все, active — только активные за последние 30 дней, year=22 — набор, spec=ИТ — поток, 22ИТ-1 — группа. Условия можно сочетать, например:\n/broadcast active year=22 spec=ИТ\nЗавтра бот будет недоступен с 02:00 до 03:00.",
+	"err_broadcast":            "Ошибка рассылки: %s",
+	"broadcast_no_recipients":  "По этим условиям получателей нет.",
+	"broadcast_preview":        "<b>Предпросмотр рассылки</b>\nПолучатели: %s\nВсего: %d",
+	"btn_broadcast_send":       "📨 Отправить",
+	"btn_broadcast_cancel":     "✖️ Отменить",
+	"broadcast_everyone":       "все пользователи",
+	"broadcast_active_only":    "только активные",
+	"broadcast_started":        "Рассылка запущена, получателей: %d. По завершении придёт отчёт.",
+	"broadcast_cancelled":      "Рассылка отменена.",
+	"broadcast_done":           "Рассылка #%d завершена.\nДоставлено: %d\nЗаблокировали бота: %d\nОшибок: %d",
+	"stats_dau":                "Активны за сутки: %d",
+	"stats_wau":                "Активны за неделю: %d",
+	"stats_mau":                "Активны за месяц: %d",
+	"stats_groups":             "Пользователи по группам",
+	"stats_features":           "Популярные функции за месяц",
+	"stats_errors":             "Ошибок за сутки: %d",
+	"stats_latency":            "Время показа расписания за неделю (%d просмотров): p50 %v, p95 %v, p99 %v",
+	"btn_report":               "⚠️ Сообщить об ошибке",
+	"btn_cancel":               "✖️ Отменить",
+	"btn_close_report":         "✅ Закрыть",
+	"report_prompt":            "Опишите одним сообщением, что не так в расписании группы %s на %s: например, неверная аудитория или пара. Сообщение получат администраторы.",
+	"report_cancelled":         "Сообщение об ошибке отменено.",
+	"report_sent":              "Спасибо! Сообщение #%d отправлено администраторам.",
+	"err_report":               "Ошибка отправки сообщения: %s",
+	"report_forward":           "⚠️ <b>Сообщение об ошибке #%d</b>\nОт: %s\nГруппа: %s\nПросмотр: %s, %s",
+	"report_view_day":          "день",
+	"report_view_week":         "неделя",
+	"report_reply_hint":        "Ответьте на это сообщение, чтобы написать пользователю, или отправьте /reply %d текст.",
+	"reply_usage":              "Укажите номер сообщения и текст ответа, например: /reply 12 Спасибо, исправили",
+	"report_not_found":         "Сообщение #%d не найдено.",
+	"report_reply":             "💬 Ответ администратора на ваше сообщение #%d:",
+	"report_reply_sent":        "Ответ на сообщение #%d отправлен.",
+	"report_closed":            "Ваше сообщение об ошибке #%d закрыто. Спасибо за помощь!",
+	"report_closed_admin":      "Сообщение #%d закрыто",
+	"doc_day":                  "День",
+	"doc_time":                 "Время",
+	"doc_subject":              "Дисциплина",
+	"doc_room":                 "Аудитория",
+	"doc_teacher":              "Преподаватель",
+	"doc_subgroup":             "Подгруппа",
+	"doc_group":                "Группа",
+	"doc_updated_at":           "Данные актуальны на %s",
+	"doc_page":                 "Стр. %d",
+	"doc_week":                 "Неделя %s – %s",
+	"lesson_lecture":           "Лекция",
+	"lesson_practice":          "Практика",
+	"lesson_lab":               "Лабораторная",
+	"lesson_exam":              "Зачёт / экзамен",
+	"lesson_other":             "Другое",
+}
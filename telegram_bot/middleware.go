@@ -0,0 +1,72 @@
+package telegram_bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+func withSender(dbConn *pg.DB) telebot.MiddlewareFunc {
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(c telebot.Context) error {
+			sender := c.Sender()
+			if sender == nil {
+				return next(c)
+			}
+
+			var user db.Users
+			err := dbConn.Model(&user).Where("telegram_id = ?", sender.ID).Select()
+			switch {
+			case err == nil:
+				c.Set("user", &user)
+				touchUser(dbConn, &user, time.Now())
+			case errors.Is(err, pg.ErrNoRows):
+				c.Set("user", &db.Users{TelegramID: sender.ID})
+			default:
+				// Without the user the terms and ban checks can't run, so the
+				// update is refused rather than let through.
+				fmt.Printf("Failed to load user %d: %v\n", sender.ID, err)
+				return refuseUpdate(c, err)
+			}
+
+			c.Set("lang", langOf(c))
+			return next(c)
+		}
+	}
+}
+
+func refuseUpdate(c telebot.Context, err error) error {
+	trackEvent(c, db.EventError, "err_generic", "")
+	switch {
+	case c.Query() != nil:
+		return c.Answer(&telebot.QueryResponse{IsPersonal: true})
+	case c.Callback() != nil:
+		return c.Respond(&telebot.CallbackResponse{Text: t(c, "err_generic", err.Error()), ShowAlert: true})
+	case c.Message() != nil:
+		return c.Send(t(c, "err_generic", escapeHTML(err.Error())))
+	}
+	return nil
+}
+
+func senderUser(c telebot.Context) *db.Users {
+	user, _ := c.Get("user").(*db.Users)
+	return user
+}
+
+// touchUser records activity at most once per lastSeenInterval so that
+// regular use of the bot doesn't turn every update into a write.
+func touchUser(dbConn *pg.DB, user *db.Users, now time.Time) {
+	if now.Sub(user.LastSeenAt) < lastSeenInterval {
+		return
+	}
+
+	user.LastSeenAt = now
+	_, err := dbConn.Model(user).Set("last_seen_at = ?last_seen_at").WherePK().Update()
+	if err != nil {
+		fmt.Printf("Failed to update last seen time for user %d: %v\n", user.TelegramID, err)
+	}
+}
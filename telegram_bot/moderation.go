@@ -0,0 +1,241 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	banModeShuffle = "shuffle"
+	banModeIgnore  = "ignore"
+	banModeRefuse  = "refuse"
+)
+
+const modLogLimit = 20
+
+func handleModeration(bot *telebot.Bot, dbConn *pg.DB, cfg Config) {
+	admin := bot.Group()
+	admin.Use(requireRole(db.RoleAdmin))
+
+	admin.Handle("/ban", func(c telebot.Context) error {
+		args := strings.Fields(c.Message().Payload)
+		if len(args) < 1 {
+			return c.Send(t(c, "ban_usage"))
+		}
+		userID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return c.Send(t(c, "ban_usage"))
+		}
+
+		reason := strings.Join(args[1:], " ")
+		if err := moderate(dbConn, c.Sender().ID, userID, db.ActionBan, reason, time.Time{}); err != nil {
+			return c.Send(errText(c, "err_moderation", escapeHTML(err.Error())))
+		}
+		return c.Send(t(c, "user_banned", userID))
+	})
+
+	admin.Handle("/tempban", func(c telebot.Context) error {
+		args := strings.Fields(c.Message().Payload)
+		if len(args) < 3 {
+			return c.Send(t(c, "tempban_usage"))
+		}
+		userID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return c.Send(t(c, "tempban_usage"))
+		}
+		duration, err := parseBanDuration(args[1])
+		if err != nil {
+			return c.Send(t(c, "tempban_usage"))
+		}
+
+		until := time.Now().Add(duration)
+		reason := strings.Join(args[2:], " ")
+		if err := moderate(dbConn, c.Sender().ID, userID, db.ActionTempBan, reason, until); err != nil {
+			return c.Send(errText(c, "err_moderation", escapeHTML(err.Error())))
+		}
+		return c.Send(t(c, "user_tempbanned", userID, until.Format("02.01.2006 15:04")))
+	})
+
+	admin.Handle("/unban", func(c telebot.Context) error {
+		userID, err := strconv.ParseInt(strings.TrimSpace(c.Message().Payload), 10, 64)
+		if err != nil {
+			return c.Send(t(c, "unban_usage"))
+		}
+
+		if err := moderate(dbConn, c.Sender().ID, userID, db.ActionUnban, "", time.Time{}); err != nil {
+			return c.Send(errText(c, "err_moderation", escapeHTML(err.Error())))
+		}
+		return c.Send(t(c, "user_unbanned", userID))
+	})
+
+	admin.Handle("/modlog", func(c telebot.Context) error {
+		return sendPages(c, modLogText(dbConn, langOf(c)))
+	})
+}
+
+func banMode(mode string) string {
+	switch mode {
+	case "", banModeShuffle:
+		return banModeShuffle
+	case banModeIgnore, banModeRefuse:
+		return mode
+	default:
+		fmt.Printf("Unknown ban mode %q, using %q\n", mode, banModeShuffle)
+		return banModeShuffle
+	}
+}
+
+// enforceBan applies the configured ban mode to every update. In shuffle
+// mode banned users keep using the bot and the schedule views scramble the
+// text themselves (see scheduleViewer.obfuscate), while exports are refused
+// by denyBanned.
+func enforceBan(cfg Config) telebot.MiddlewareFunc {
+	mode := banMode(cfg.BanMode)
+
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(c telebot.Context) error {
+			user := senderUser(c)
+			if mode == banModeShuffle || user == nil || roleOf(c) == db.RoleAdmin || !user.IsBannedAt(time.Now()) {
+				return next(c)
+			}
+
+			if mode == banModeIgnore {
+				if c.Callback() != nil {
+					return c.Respond()
+				}
+				return nil
+			}
+
+			notice := bannedNotice(langOf(c), user)
+			switch {
+			case c.Query() != nil:
+				return c.Answer(&telebot.QueryResponse{
+					IsPersonal:        true,
+					SwitchPMText:      t(c, "inline_banned"),
+					SwitchPMParameter: "banned",
+				})
+			case c.Callback() != nil:
+				return c.Respond(&telebot.CallbackResponse{Text: notice, ShowAlert: true})
+			}
+			return c.Send(escapeHTML(notice))
+		}
+	}
+}
+
+// denyBanned keeps banned users away from exports and feeds. Shuffle mode
+// only scrambles text views, so files would still carry the real schedule.
+func denyBanned(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		user := senderUser(c)
+		if user == nil || roleOf(c) == db.RoleAdmin || !user.IsBannedAt(time.Now()) {
+			return next(c)
+		}
+
+		if c.Callback() != nil {
+			return c.Respond(&telebot.CallbackResponse{Text: t(c, "export_unavailable"), ShowAlert: true})
+		}
+		return c.Send(t(c, "export_unavailable"))
+	}
+}
+
+func bannedNotice(lang string, user *db.Users) string {
+	notice := tr(lang, "banned_notice")
+	if !user.BannedUntil.IsZero() {
+		notice += "\n" + tr(lang, "banned_until", user.BannedUntil.Format("02.01.2006 15:04"))
+	}
+	if user.BanReason != "" {
+		notice += "\n" + tr(lang, "banned_reason", user.BanReason)
+	}
+	return notice
+}
+
+func parseBanDuration(value string) (time.Duration, error) {
+	if len(value) < 2 {
+		return 0, errors.New("invalid duration")
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return 0, errors.New("invalid duration")
+		}
+		return duration, nil
+	}
+
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || count <= 0 {
+		return 0, errors.New("invalid duration")
+	}
+	return time.Duration(count) * unit, nil
+}
+
+func moderate(dbConn *pg.DB, adminID, userID int64, action, reason string, until time.Time) error {
+	user := &db.Users{
+		TelegramID:  userID,
+		IsBanned:    action != db.ActionUnban,
+		BannedUntil: until,
+		BanReason:   reason,
+	}
+
+	return dbConn.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.Model(user).
+			OnConflict("(telegram_id) DO UPDATE").
+			Set("is_banned = EXCLUDED.is_banned").
+			Set("banned_until = EXCLUDED.banned_until").
+			Set("ban_reason = EXCLUDED.ban_reason").
+			Insert()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Model(&db.ModerationAction{
+			AdminID:   adminID,
+			UserID:    userID,
+			Action:    action,
+			Reason:    reason,
+			Until:     until,
+			CreatedAt: time.Now(),
+		}).Insert()
+		return err
+	})
+}
+
+func modLogText(dbConn *pg.DB, lang string) string {
+	var actions []db.ModerationAction
+	err := dbConn.Model(&actions).Order("created_at DESC").Limit(modLogLimit).Select()
+	if err != nil {
+		return errTr(lang, "err_modlog", escapeHTML(err.Error()))
+	}
+	if len(actions) == 0 {
+		return tr(lang, "modlog_empty")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(bold(tr(lang, "modlog_header")) + "\n\n")
+	for _, action := range actions {
+		sb.WriteString(fmt.Sprintf("%s — %d → %d: %s",
+			action.CreatedAt.Format("02.01.2006 15:04"), action.AdminID, action.UserID, action.Action))
+		if !action.Until.IsZero() {
+			sb.WriteString(" " + tr(lang, "modlog_until", action.Until.Format("02.01.2006 15:04")))
+		}
+		if action.Reason != "" {
+			sb.WriteString(" — " + escapeHTML(action.Reason))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
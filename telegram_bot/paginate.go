@@ -0,0 +1,231 @@
+package telegram_bot
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"gopkg.in/telebot.v3"
+)
+
+const maxMessageLength = 4096
+
+func messageLength(text string) int {
+	n := 0
+	for _, r := range text {
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return n
+}
+
+func splitMessage(text string) []string {
+	if messageLength(text) <= maxMessageLength {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if part := strings.TrimRight(current.String(), "\n"); strings.TrimSpace(part) != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for messageLength(line) > maxMessageLength {
+			flush()
+			var head string
+			head, line = cutHTML(line, maxMessageLength)
+			parts = append(parts, head)
+		}
+		if messageLength(current.String())+messageLength(line) > maxMessageLength {
+			flush()
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	return parts
+}
+
+// cutHTML splits a single line that is too long for one message. The cut is
+// made outside tags and entities, preferably at a space, and tags that are
+// open at that point are closed in the head and reopened in the tail.
+func cutHTML(text string, limit int) (string, string) {
+	var open, safeOpen, spaceOpen []string
+	safe, space := 0, 0
+	n := 0
+
+	for i := 0; i < len(text); {
+		token := text[i : i+utf8RuneLen(text[i:])]
+		switch text[i] {
+		case '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				token = text[i : i+end+1]
+			}
+		case '&':
+			if end := strings.IndexByte(text[i:], ';'); end > 0 && end <= 10 {
+				token = text[i : i+end+1]
+			}
+		}
+
+		next := open
+		if strings.HasPrefix(token, "</") {
+			next = open[:max(len(open)-1, 0)]
+		} else if strings.HasPrefix(token, "<") && len(token) > 1 {
+			next = append(slices.Clone(open), token)
+		}
+
+		if n+messageLength(token)+closingLength(next) > limit {
+			break
+		}
+		n += messageLength(token)
+		i += len(token)
+		open = next
+
+		safe, safeOpen = i, open
+		if token == " " || token == "\n" {
+			space, spaceOpen = i, open
+		}
+	}
+
+	if safe == 0 {
+		// Only a tag can be longer than a whole message. It can't be sent
+		// anywhere, so it is dropped and the text inside it is kept.
+		if text[0] == '<' && strings.IndexByte(text, '>') > 0 {
+			return cutHTML(dropLeadingTag(text), limit)
+		}
+		size := utf8RuneLen(text)
+		return text[:size], text[size:]
+	}
+	if space > safe/2 {
+		safe, safeOpen = space, spaceOpen
+	}
+
+	head, tail := text[:safe], text[safe:]
+	for i := len(safeOpen) - 1; i >= 0; i-- {
+		head += closingTag(safeOpen[i])
+	}
+	return head, strings.Join(safeOpen, "") + tail
+}
+
+// dropLeadingTag removes the tag text starts with and, for an opening tag,
+// the tag that closes it.
+func dropLeadingTag(text string) string {
+	end := strings.IndexByte(text, '>')
+	tag, rest := text[:end+1], text[end+1:]
+	if strings.HasPrefix(tag, "</") {
+		return rest
+	}
+
+	depth := 0
+	for i := 0; i < len(rest); i++ {
+		if rest[i] != '<' {
+			continue
+		}
+		if !strings.HasPrefix(rest[i:], "</") {
+			depth++
+			continue
+		}
+		if depth > 0 {
+			depth--
+			continue
+		}
+		if end := strings.IndexByte(rest[i:], '>'); end > 0 {
+			return rest[:i] + rest[i+end+1:]
+		}
+		break
+	}
+	return rest
+}
+
+func utf8RuneLen(s string) int {
+	_, size := utf8.DecodeRuneInString(s)
+	return size
+}
+
+func closingTag(openTag string) string {
+	name := strings.TrimSuffix(strings.TrimPrefix(openTag, "<"), ">")
+	name, _, _ = strings.Cut(name, " ")
+	return "</" + name + ">"
+}
+
+func closingLength(open []string) int {
+	n := 0
+	for _, tag := range open {
+		n += len(closingTag(tag))
+	}
+	return n
+}
+
+func weekPages(header string, schedules []db.Schedule, prefs db.ViewPrefs, lang string) []string {
+	return dayPages(header, weekDays(schedules, weekFormat(prefs, lang)), lang)
+}
+
+func dayPages(header string, days []dayView, lang string) []string {
+	full := header + renderTemplate(lang, weekTemplate, days)
+	if messageLength(full) <= maxMessageLength {
+		return []string{full}
+	}
+
+	var pages []string
+	current := ""
+	for _, day := range days {
+		dayText := renderTemplate(lang, weekTemplate, []dayView{day})
+		if current != "" && messageLength(header+current+dayText) > maxMessageLength {
+			pages = append(pages, splitMessage(header+current)...)
+			current = ""
+		}
+		current += dayText
+	}
+	if current != "" {
+		pages = append(pages, splitMessage(header+current)...)
+	}
+
+	return pages
+}
+
+func sendSplit(bot *telebot.Bot, chatID int64, text string) error {
+	for _, part := range splitMessage(text) {
+		if _, err := sendThrottled(bot, chatID, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sendPages(c telebot.Context, text string, opts ...interface{}) error {
+	parts := splitMessage(text)
+	for i, part := range parts {
+		if i < len(parts)-1 {
+			if err := c.Send(part); err != nil {
+				return err
+			}
+			continue
+		}
+		return c.Send(part, opts...)
+	}
+	return nil
+}
+
+func pageButtons(unique, date, group string, page, pages int) []telebot.Btn {
+	if pages < 2 {
+		return nil
+	}
+
+	buttons := make([]telebot.Btn, 0, pages)
+	for i := 0; i < pages; i++ {
+		text := strconv.Itoa(i + 1)
+		if i == page {
+			text = "· " + text + " ·"
+		}
+		buttons = append(buttons, telebot.Btn{Text: text, Unique: unique, Data: pageData(date, group, i)})
+	}
+	return buttons
+}
@@ -0,0 +1,180 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+func TestMessageLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"привет", 6},
+		{"📖", 2},
+		{"a📖b", 4},
+	}
+	for _, tt := range tests {
+		if got := messageLength(tt.in); got != tt.want {
+			t.Errorf("messageLength(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMessageBoundaries(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+
+	tests := []struct {
+		name  string
+		text  string
+		parts int
+	}{
+		{"empty", "", 1},
+		{"exact limit", strings.Repeat("x", maxMessageLength), 1},
+		{"exact limit with emoji", strings.Repeat("📖", maxMessageLength/2), 1},
+		{"one over", strings.Repeat(line, 40) + strings.Repeat("x", 97), 2},
+		{"emoji one over", strings.Repeat("📖", maxMessageLength/2-1) + "\nxxx", 2},
+		{"many lines", strings.Repeat(line, 100), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := splitMessage(tt.text)
+			if len(parts) != tt.parts {
+				t.Fatalf("got %d parts, want %d", len(parts), tt.parts)
+			}
+			for i, part := range parts {
+				if n := messageLength(part); n > maxMessageLength {
+					t.Errorf("part %d is %d long", i, n)
+				}
+			}
+			if tt.parts > 1 && strings.Join(parts, "\n") != strings.TrimRight(tt.text, "\n") {
+				t.Errorf("parts do not add up to the original text")
+			}
+		})
+	}
+}
+
+func TestSplitMessageKeepsLinesWhole(t *testing.T) {
+	var lines []string
+	for i := 0; i < 300; i++ {
+		lines = append(lines, fmt.Sprintf("<b>%03d</b> <i>line &amp; text</i>", i))
+	}
+
+	parts := splitMessage(strings.Join(lines, "\n"))
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want several", len(parts))
+	}
+	for _, part := range parts {
+		checkTelegramHTML(t, part)
+		for _, line := range strings.Split(part, "\n") {
+			if !strings.HasPrefix(line, "<b>") || !strings.HasSuffix(line, "</i>") {
+				t.Fatalf("line was split: %q", line)
+			}
+		}
+	}
+}
+
+func TestDayPages(t *testing.T) {
+	header := groupHeader("ru", "22-ИТ-1")
+
+	schedules := func(days, lessons int) []db.Schedule {
+		var result []db.Schedule
+		for d := 0; d < days; d++ {
+			for l := 0; l < lessons; l++ {
+				result = append(result, db.Schedule{
+					GroupName:  "22-ИТ-1",
+					LessonDate: fmt.Sprintf("%02d.09", d+1),
+					DayOfWeek:  "Понедельник",
+					LessonTime: "08:30-09:50",
+					LessonName: fmt.Sprintf("Lesson %d <%d> & %s", l, d, strings.Repeat("_", 60)),
+					Location:   "1-101",
+					Teacher:    "Иванов Иван Иванович",
+				})
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		days    int
+		lessons int
+		pages   int
+	}{
+		{"single page", 6, 2, 1},
+		{"page per day", 6, 14, 6},
+		{"several days per page", 12, 5, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := weekDays(schedules(tt.days, tt.lessons), weekFormat(db.ViewPrefs{Layout: db.LayoutDetailed}, "ru"))
+			pages := dayPages(header, days, "ru")
+			if len(pages) != tt.pages {
+				t.Fatalf("got %d pages, want %d", len(pages), tt.pages)
+			}
+
+			seen := 0
+			for i, page := range pages {
+				if n := messageLength(page); n > maxMessageLength {
+					t.Errorf("page %d is %d long", i, n)
+				}
+				if !strings.HasPrefix(page, header) {
+					t.Errorf("page %d has no header", i)
+				}
+				checkTelegramHTML(t, page)
+				seen += strings.Count(page, "<b>Понедельник</b>")
+			}
+			if seen != tt.days {
+				t.Errorf("pages contain %d days, want %d", seen, tt.days)
+			}
+		})
+	}
+}
+
+func TestSplitMessageLongLine(t *testing.T) {
+	tagRe := regexp.MustCompile(`<[^>]*>`)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"plain words", strings.Repeat("word ", 2000)},
+		{"no spaces", strings.Repeat("x", 3*maxMessageLength)},
+		{"entities", strings.Repeat("a&amp;b&lt;c&gt; ", 800)},
+		{"inside tag", "<b>" + strings.Repeat("bold &amp; text ", 700) + "</b>"},
+		{"nested tags", strings.Repeat("<b>Пара</b>: <i>"+strings.Repeat("&quot;x&quot; ", 40)+"</i>; ", 30)},
+		{"link", `<a href="https://example.com/?a=1&amp;b=2">` + strings.Repeat("link ", 1500) + "</a>"},
+		{"oversized leading tag", `<a href="https://example.com/?q=` + strings.Repeat("x", maxMessageLength) + `">` + strings.Repeat("link ", 1500) + "</a> after <b>bold</b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkTelegramHTML(t, tt.text)
+
+			parts := splitMessage(tt.text)
+			if len(parts) < 2 {
+				t.Fatalf("got %d parts, want several", len(parts))
+			}
+
+			var plain strings.Builder
+			for i, part := range parts {
+				if n := messageLength(part); n > maxMessageLength {
+					t.Errorf("part %d is %d long", i, n)
+				}
+				checkTelegramHTML(t, part)
+				plain.WriteString(tagRe.ReplaceAllString(part, ""))
+			}
+			if want := tagRe.ReplaceAllString(tt.text, ""); plain.String() != want {
+				t.Errorf("parts do not add up to the original text")
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package telegram_bot
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const subjectSearchLimit = 5
+
+type queryKind int
+
+const (
+	queryUnknown queryKind = iota
+	queryDay
+	queryWeek
+	querySubject
+)
+
+type parsedQuery struct {
+	kind    queryKind
+	date    time.Time
+	subject string
+}
+
+type lessonOccurrence struct {
+	schedule db.Schedule
+	start    time.Time
+}
+
+var (
+	queryDateRegex = regexp.MustCompile(`^(\d{1,2})[./](\d{1,2})(?:[./](\d{2}|\d{4}))?$`)
+
+	relativeDays = map[string]int{
+		"позавчера":   -2,
+		"пазаучора":   -2,
+		"вчера":       -1,
+		"учора":       -1,
+		"вчора":       -1,
+		"сегодня":     0,
+		"сення":       0,
+		"завтра":      1,
+		"заутра":      1,
+		"послезавтра": 2,
+		"паслязаутра": 2,
+	}
+
+	weekdayAbbreviations = map[string]time.Weekday{
+		"пн":  time.Monday,
+		"вт":  time.Tuesday,
+		"аут": time.Tuesday,
+		"ср":  time.Wednesday,
+		"чт":  time.Thursday,
+		"чц":  time.Thursday,
+		"пт":  time.Friday,
+		"сб":  time.Saturday,
+		"вс":  time.Sunday,
+		"нд":  time.Sunday,
+	}
+
+	weekdayStems = []struct {
+		stem    string
+		weekday time.Weekday
+	}{
+		{"понедел", time.Monday},
+		{"панядзел", time.Monday},
+		{"вторн", time.Tuesday},
+		{"аутор", time.Tuesday},
+		{"сред", time.Wednesday},
+		{"серад", time.Wednesday},
+		{"четв", time.Thursday},
+		{"чацв", time.Thursday},
+		{"пятн", time.Friday},
+		{"суббот", time.Saturday},
+		{"субот", time.Saturday},
+		{"воскрес", time.Sunday},
+		{"нядзел", time.Sunday},
+	}
+
+	nextStems    = []string{"следующ", "след", "наступн"}
+	weekStems    = []string{"недел", "тыдз", "тыдн"}
+	subjectWords = map[string]struct{}{
+		"когда": {},
+		"кали":  {},
+		"найди": {},
+		"найти": {},
+		"где":   {},
+		"дзе":   {},
+	}
+	fillerWords = map[string]struct{}{
+		"в":          {},
+		"во":         {},
+		"у":          {},
+		"на":         {},
+		"эту":        {},
+		"этой":       {},
+		"гэты":       {},
+		"гэтым":      {},
+		"расписание": {},
+		"расклад":    {},
+		"пары":       {},
+		"пар":        {},
+		"какие":      {},
+		"якия":       {},
+		"что":        {},
+		"што":        {},
+		"покажи":     {},
+		"пакажы":     {},
+		"будет":      {},
+		"будзе":      {},
+		"пара":       {},
+	}
+)
+
+func normalizeQueryText(text string) string {
+	replacer := strings.NewReplacer("ё", "е", "ў", "у", "і", "и", "'", "", "’", "", "ʼ", "")
+	return replacer.Replace(strings.ToLower(strings.TrimSpace(text)))
+}
+
+func queryTokens(text string) []string {
+	return strings.FieldsFunc(normalizeQueryText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '/' && r != '-'
+	})
+}
+
+func hasAnyPrefix(token string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(token, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekday(token string) (time.Weekday, bool) {
+	if weekday, ok := weekdayAbbreviations[strings.TrimSuffix(token, ".")]; ok {
+		return weekday, true
+	}
+
+	for _, entry := range weekdayStems {
+		if strings.HasPrefix(token, entry.stem) {
+			return entry.weekday, true
+		}
+	}
+
+	return 0, false
+}
+
+func parseQueryDate(token string, now time.Time) (time.Time, bool) {
+	matches := queryDateRegex.FindStringSubmatch(strings.Trim(token, "./"))
+	if matches == nil {
+		return time.Time{}, false
+	}
+
+	day, _ := strconv.Atoi(matches[1])
+	month, _ := strconv.Atoi(matches[2])
+	year := db.LessonYear(fmt.Sprintf("%02d.%02d", day, month), now)
+	if matches[3] != "" {
+		year, _ = strconv.Atoi(matches[3])
+		if year < 100 {
+			year += 2000
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if date.Day() != day || int(date.Month()) != month {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+func parseQuery(text string, now time.Time) parsedQuery {
+	tokens := queryTokens(text)
+	if len(tokens) == 0 {
+		return parsedQuery{kind: queryUnknown}
+	}
+
+	if _, ok := subjectWords[tokens[0]]; ok {
+		subject := strings.Join(tokens[1:], " ")
+		if subject == "" {
+			return parsedQuery{kind: queryUnknown}
+		}
+		return parsedQuery{kind: querySubject, subject: subject}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var (
+		next    bool
+		week    bool
+		weekday *time.Weekday
+		date    *time.Time
+	)
+
+	for _, token := range tokens {
+		if _, ok := fillerWords[token]; ok {
+			continue
+		}
+
+		if offset, ok := relativeDays[token]; ok {
+			d := today.AddDate(0, 0, offset)
+			date = &d
+			continue
+		}
+
+		if d, ok := parseQueryDate(token, now); ok {
+			date = &d
+			continue
+		}
+
+		if hasAnyPrefix(token, nextStems) {
+			next = true
+			continue
+		}
+
+		if hasAnyPrefix(token, weekStems) {
+			week = true
+			continue
+		}
+
+		if wd, ok := parseWeekday(token); ok {
+			weekday = &wd
+			continue
+		}
+
+		return parsedQuery{kind: queryUnknown}
+	}
+
+	switch {
+	case date != nil:
+		if week {
+			return parsedQuery{kind: queryWeek, date: *date}
+		}
+		return parsedQuery{kind: queryDay, date: *date}
+	case weekday != nil:
+		delta := (int(*weekday) - int(today.Weekday()) + 7) % 7
+		if next {
+			monday := today
+			for monday.Weekday() != time.Monday {
+				monday = monday.AddDate(0, 0, -1)
+			}
+			return parsedQuery{kind: queryDay, date: monday.AddDate(0, 0, 7+(int(*weekday)+6)%7)}
+		}
+		return parsedQuery{kind: queryDay, date: today.AddDate(0, 0, delta)}
+	case week:
+		if next {
+			return parsedQuery{kind: queryWeek, date: today.AddDate(0, 0, 7)}
+		}
+		return parsedQuery{kind: queryWeek, date: today}
+	}
+
+	return parsedQuery{kind: queryUnknown}
+}
+
+func handleTextQuery(c telebot.Context, dbConn *pg.DB) error {
+	text := c.Text()
+	if isGroupChat(c.Chat()) {
+		var addressed bool
+		text, addressed = addressedText(c)
+		if !addressed {
+			return nil
+		}
+	}
+
+	query := parseQuery(text, time.Now())
+
+	switch query.kind {
+	case queryDay:
+		return sendDaySchedule(c, dbConn, query.date)
+	case queryWeek:
+		return sendWeekSchedule(c, dbConn, query.date)
+	case querySubject:
+		return sendSubjectSearch(c, dbConn, query.subject)
+	}
+
+	return c.Send(t(c, "query_not_understood"))
+}
+
+func sendSubjectSearch(c telebot.Context, dbConn *pg.DB, subject string) error {
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return c.Send(t(c, "find_usage"))
+	}
+
+	viewer, err := resolveViewer(c, dbConn, "")
+	if err != nil {
+		return c.Send(noGroupText(c))
+	}
+
+	lessons, err := getUpcomingLessons(dbConn, viewer.groupName, viewer.subgroup, time.Now())
+	if err != nil {
+		return c.Send(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+
+	found := findSubjectLessons(lessons, subject, subjectSearchLimit)
+	trackEvent(c, db.EventView, "find", viewer.groupName)
+	if len(found) == 0 {
+		return c.Send(t(c, "subject_not_found", escapeHTML(subject)))
+	}
+
+	return sendPages(c, viewer.obfuscate(formatSubjectSearch(found, subject, viewer.lang)))
+}
+
+func findSubjectLessons(lessons []lessonOccurrence, subject string, limit int) []lessonOccurrence {
+	var found []lessonOccurrence
+	for _, lesson := range lessons {
+		if !matchesSubject(lesson.schedule.LessonName, subject) {
+			continue
+		}
+		found = append(found, lesson)
+		if len(found) == limit {
+			break
+		}
+	}
+	return found
+}
+
+func matchesSubject(lessonName, query string) bool {
+	name := normalizeQueryText(lessonName)
+	query = normalizeQueryText(query)
+	if query == "" {
+		return false
+	}
+	if strings.Contains(name, query) {
+		return true
+	}
+
+	nameWords := subjectWordsOf(name)
+	queryWords := subjectWordsOf(query)
+	if len(nameWords) == 0 || len(queryWords) == 0 {
+		return false
+	}
+
+	if len(queryWords) == 1 && matchesInitials(nameWords, queryWords[0]) {
+		return true
+	}
+
+	for _, queryWord := range queryWords {
+		matched := false
+		for _, nameWord := range nameWords {
+			if wordsSimilar(nameWord, queryWord) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func subjectWordsOf(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchesInitials(nameWords []string, query string) bool {
+	queryRunes := []rune(query)
+	if len(queryRunes) < 2 || len(queryRunes) > len(nameWords) {
+		return false
+	}
+
+	var initials []rune
+	for _, word := range nameWords {
+		initials = append(initials, []rune(word)[0])
+	}
+
+	return strings.HasPrefix(string(initials), query)
+}
+
+func wordsSimilar(nameWord, queryWord string) bool {
+	nameRunes, queryRunes := []rune(nameWord), []rune(queryWord)
+
+	if len(queryRunes) >= 3 && strings.HasPrefix(nameWord, queryWord) {
+		return true
+	}
+
+	maxDistance := 0
+	switch {
+	case len(queryRunes) >= 8:
+		maxDistance = 2
+	case len(queryRunes) >= 5:
+		maxDistance = 1
+	}
+	if maxDistance == 0 {
+		return nameWord == queryWord
+	}
+
+	if levenshtein(nameRunes, queryRunes) <= maxDistance {
+		return true
+	}
+	if len(nameRunes) > len(queryRunes) {
+		return levenshtein(nameRunes[:len(queryRunes)], queryRunes) <= maxDistance
+	}
+
+	return false
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func formatSubjectSearch(lessons []lessonOccurrence, subject, lang string) string {
+	return renderTemplate(lang, searchTemplate, searchView{Query: subject, Lessons: searchLessons(lessons, lang), L: catalog(lang)})
+}
+
+func getUpcomingLessons(dbConn *pg.DB, groupName, subgroup string, now time.Time) ([]lessonOccurrence, error) {
+	schedules, err := getGroupSchedules(dbConn, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	return upcomingLessons(filterBySubgroup(schedules, subgroup), now), nil
+}
+
+func upcomingLessons(schedules []db.Schedule, now time.Time) []lessonOccurrence {
+	var lessons []lessonOccurrence
+	for _, schedule := range schedules {
+		start, _, err := schedule.Period(db.LessonYear(schedule.LessonDate, now), now.Location())
+		if err != nil || !start.After(now) {
+			continue
+		}
+		lessons = append(lessons, lessonOccurrence{schedule: schedule, start: start})
+	}
+
+	sort.Slice(lessons, func(i, j int) bool {
+		return lessons[i].start.Before(lessons[j].start)
+	})
+
+	return lessons
+}
@@ -0,0 +1,129 @@
+package telegram_bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+func TestUpcomingLessonsSkipPastTerm(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	schedules := []db.Schedule{
+		{LessonDate: "15.09", LessonTime: "09:00-10:20", LessonName: "Autumn"},
+		{LessonDate: "10.03", LessonTime: "08:00-09:20", LessonName: "Earlier today"},
+		{LessonDate: "12.03", LessonTime: "09:00-10:20", LessonName: "Thursday"},
+		{LessonDate: "10.03", LessonTime: "13:00-14:20", LessonName: "Later today"},
+	}
+
+	lessons := upcomingLessons(schedules, now)
+
+	want := []string{"Later today", "Thursday"}
+	if len(lessons) != len(want) {
+		t.Fatalf("got %d upcoming lessons %+v, want %v", len(lessons), lessons, want)
+	}
+	for i, name := range want {
+		if lessons[i].schedule.LessonName != name {
+			t.Errorf("lesson %d is %q, want %q", i, lessons[i].schedule.LessonName, name)
+		}
+		if lessons[i].start.Year() != 2026 {
+			t.Errorf("lesson %q resolved to %v", name, lessons[i].start)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	monday := time.Date(2025, time.October, 13, 10, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	december := time.Date(2025, time.December, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		text    string
+		now     time.Time
+		kind    queryKind
+		date    time.Time
+		subject string
+	}{
+		{name: "tomorrow", text: "завтра", now: monday, kind: queryDay, date: date(2025, time.October, 14)},
+		{name: "day after tomorrow in belarusian", text: "паслязаўтра", now: monday, kind: queryDay, date: date(2025, time.October, 15)},
+		{name: "weekday abbreviation", text: "пт", now: monday, kind: queryDay, date: date(2025, time.October, 17)},
+		{name: "today's weekday", text: "в понедельник", now: monday, kind: queryDay, date: date(2025, time.October, 13)},
+		{name: "next tuesday on monday", text: "следующий вторник", now: monday, kind: queryDay, date: date(2025, time.October, 21)},
+		{name: "next tuesday on tuesday", text: "следующий вторник", now: tuesday, kind: queryDay, date: date(2025, time.October, 21)},
+		{name: "next week in belarusian", text: "наступны тыдзень", now: monday, kind: queryWeek, date: date(2025, time.October, 20)},
+		{name: "this week", text: "расписание на неделю", now: monday, kind: queryWeek, date: date(2025, time.October, 13)},
+		{name: "date", text: "15.10", now: monday, kind: queryDay, date: date(2025, time.October, 15)},
+		{name: "january date in december", text: "15.01", now: december, kind: queryDay, date: date(2026, time.January, 15)},
+		{name: "date with year", text: "15.01.2025", now: december, kind: queryDay, date: date(2025, time.January, 15)},
+		{name: "week of date", text: "неделя 15.10", now: monday, kind: queryWeek, date: date(2025, time.October, 15)},
+		{name: "invalid date", text: "31.02", now: monday, kind: queryUnknown},
+		{name: "subject in belarusian", text: "кали фізіка", now: monday, kind: querySubject, subject: "физика"},
+		{name: "subject without name", text: "когда", now: monday, kind: queryUnknown},
+		{name: "unknown word", text: "привет", now: monday, kind: queryUnknown},
+		{name: "empty", text: "  ", now: monday, kind: queryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseQuery(tt.text, tt.now)
+			if got.kind != tt.kind || !got.date.Equal(tt.date) || got.subject != tt.subject {
+				t.Errorf("parseQuery(%q) = %d %v %q, want %d %v %q", tt.text, got.kind, got.date, got.subject, tt.kind, tt.date, tt.subject)
+			}
+		})
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		token string
+		want  time.Weekday
+		ok    bool
+	}{
+		{"пн", time.Monday, true},
+		{"вт.", time.Tuesday, true},
+		{"аут", time.Tuesday, true},
+		{"среду", time.Wednesday, true},
+		{"серада", time.Wednesday, true},
+		{"чацвер", time.Thursday, true},
+		{"пятница", time.Friday, true},
+		{"субота", time.Saturday, true},
+		{"нядзеля", time.Sunday, true},
+		{"воскресенье", time.Sunday, true},
+		{"физика", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseWeekday(tt.token)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseWeekday(%q) = %v, %v, want %v, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseQueryDate(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		token string
+		want  time.Time
+		ok    bool
+	}{
+		{"15.10", time.Date(2025, time.October, 15, 0, 0, 0, 0, time.UTC), true},
+		{"1/4", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), true},
+		{"15.10.26", time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC), true},
+		{"29.02.2024", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), true},
+		{"31.02", time.Time{}, false},
+		{"13.13", time.Time{}, false},
+		{"завтра", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseQueryDate(tt.token, now)
+		if !got.Equal(tt.want) || ok != tt.ok {
+			t.Errorf("parseQueryDate(%q) = %v, %v, want %v, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	roleRequestsLimit = 10
+	teacherMatchLimit = 10
+)
+
+func handleRoles(bot *telebot.Bot, dbConn *pg.DB, cfg Config) {
+	personal := bot.Group()
+	personal.Use(privateOnly)
+
+	personal.Handle(&telebot.Btn{Unique: "role"}, func(c telebot.Context) error {
+		return c.Edit(roleText(c), roleButtons(c))
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "request_headman"}, func(c telebot.Context) error {
+		return c.Edit(requestHeadman(c, dbConn, cfg), backMenuButtons(langOf(c)))
+	})
+
+	personal.Handle("/headman", func(c telebot.Context) error {
+		return c.Send(requestHeadman(c, dbConn, cfg))
+	})
+
+	personal.Handle("/teacher", func(c telebot.Context) error {
+		return c.Send(requestTeacher(c, dbConn, cfg, c.Message().Payload))
+	})
+
+	headman := bot.Group()
+	headman.Use(privateOnly, requireRole(db.RoleHeadman))
+
+	headman.Handle(&telebot.Btn{Unique: "announce"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "announce_help", escapeHTML(senderUser(c).RoleGroup)), backMenuButtons(langOf(c)))
+	})
+
+	headman.Handle("/announce", func(c telebot.Context) error {
+		text := strings.TrimSpace(c.Message().Payload)
+		if text == "" {
+			return c.Send(t(c, "announce_help", escapeHTML(senderUser(c).RoleGroup)))
+		}
+
+		recipients, err := groupMembers(dbConn, senderUser(c).RoleGroup, c.Sender().ID)
+		if err != nil {
+			return c.Send(errText(c, "err_announce", escapeHTML(err.Error())))
+		}
+
+		go sendAnnouncement(bot, dbConn, recipients, senderUser(c).RoleGroup, text)
+		return c.Send(t(c, "announce_sent", len(recipients)))
+	})
+
+	teacher := bot.Group()
+	teacher.Use(privateOnly, requireRole(db.RoleTeacher))
+
+	teacher.Handle(&telebot.Btn{Unique: "teacher_week"}, func(c telebot.Context) error {
+		date, _, page := parseViewData(c.Data())
+		day, _, err := parseDate(date)
+		if err != nil {
+			day = time.Now()
+		}
+		return sendTeacherWeek(c, dbConn, day, page)
+	})
+
+	teacher.Handle("/teaching", func(c telebot.Context) error {
+		return sendTeacherWeek(c, dbConn, time.Now(), 0)
+	})
+
+	admin := bot.Group()
+	admin.Use(requireRole(db.RoleAdmin))
+
+	admin.Handle(&telebot.Btn{Unique: "admin_menu"}, func(c telebot.Context) error {
+		return c.Edit(splitMessage(adminStatsText(c, dbConn))[0], adminMenuButtons(langOf(c)))
+	})
+
+	admin.Handle("/stats", func(c telebot.Context) error {
+		return sendPages(c, adminStatsText(c, dbConn))
+	})
+
+	admin.Handle(&telebot.Btn{Unique: "modlog"}, func(c telebot.Context) error {
+		return c.Edit(splitMessage(modLogText(dbConn, langOf(c)))[0], adminBackButtons(langOf(c)))
+	})
+
+	admin.Handle(&telebot.Btn{Unique: "role_requests"}, func(c telebot.Context) error {
+		text, markup := roleRequestsView(dbConn, langOf(c))
+		return c.Edit(text, markup)
+	})
+
+	admin.Handle(&telebot.Btn{Unique: "role_review"}, func(c telebot.Context) error {
+		return handleRoleReview(c, dbConn)
+	})
+}
+
+func isAdmin(adminIDs []int64, userID int64) bool {
+	for _, id := range adminIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// withRole resolves the sender's role once per update. Admins come from the
+// configuration, every other role is stored on the user.
+func withRole(adminIDs []int64) telebot.MiddlewareFunc {
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(c telebot.Context) error {
+			if c.Sender() != nil {
+				c.Set("role", userRole(adminIDs, c.Sender().ID, senderUser(c)))
+			}
+			return next(c)
+		}
+	}
+}
+
+func userRole(adminIDs []int64, userID int64, user *db.Users) string {
+	switch {
+	case isAdmin(adminIDs, userID):
+		return db.RoleAdmin
+	case user == nil:
+		return db.RoleStudent
+	case user.Role == db.RoleHeadman && user.RoleGroup != "":
+		return db.RoleHeadman
+	case user.Role == db.RoleTeacher && user.TeacherName != "":
+		return db.RoleTeacher
+	}
+	return db.RoleStudent
+}
+
+func roleOf(c telebot.Context) string {
+	if role, ok := c.Get("role").(string); ok {
+		return role
+	}
+	return db.RoleStudent
+}
+
+func requireRole(roles ...string) telebot.MiddlewareFunc {
+	return func(next telebot.HandlerFunc) telebot.HandlerFunc {
+		return func(c telebot.Context) error {
+			role := roleOf(c)
+			for _, allowed := range roles {
+				if role == allowed {
+					return next(c)
+				}
+			}
+
+			if c.Callback() != nil {
+				return c.Respond(&telebot.CallbackResponse{
+					Text:      t(c, "role_required"),
+					ShowAlert: true,
+				})
+			}
+			return c.Send(t(c, "role_required"))
+		}
+	}
+}
+
+func roleMenuButtons(lang, role string) [][]telebot.Btn {
+	switch role {
+	case db.RoleHeadman:
+		return [][]telebot.Btn{createButton(tr(lang, "btn_announce"), "announce", "")}
+	case db.RoleTeacher:
+		return [][]telebot.Btn{createButton(tr(lang, "btn_teacher_week"), "teacher_week", "")}
+	case db.RoleAdmin:
+		return [][]telebot.Btn{createButton(tr(lang, "btn_admin"), "admin_menu", "")}
+	}
+	return nil
+}
+
+func roleText(c telebot.Context) string {
+	text := t(c, "role_current", t(c, "role_"+roleOf(c)))
+	if user := senderUser(c); user != nil {
+		switch roleOf(c) {
+		case db.RoleHeadman:
+			text += "\n" + t(c, "role_headman_of", escapeHTML(user.RoleGroup))
+		case db.RoleTeacher:
+			text += "\n" + t(c, "role_teacher_as", escapeHTML(user.TeacherName))
+		}
+	}
+	return text + "\n\n" + t(c, "role_help")
+}
+
+func roleButtons(c telebot.Context) *telebot.ReplyMarkup {
+	return createMenu(1,
+		createButton(t(c, "btn_request_headman"), "request_headman", ""),
+		createButton(t(c, "btn_back"), "settings", ""),
+	)
+}
+
+func requestHeadman(c telebot.Context, dbConn *pg.DB, cfg Config) string {
+	user := senderUser(c)
+	if user == nil || user.GroupName == "" {
+		return t(c, "no_group_selected")
+	}
+	if roleOf(c) == db.RoleHeadman && user.RoleGroup == user.GroupName {
+		return t(c, "role_already")
+	}
+
+	request := &db.RoleRequest{
+		UserID:    c.Sender().ID,
+		Role:      db.RoleHeadman,
+		GroupName: user.GroupName,
+	}
+	return submitRoleRequest(c, dbConn, cfg, request)
+}
+
+func requestTeacher(c telebot.Context, dbConn *pg.DB, cfg Config, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return t(c, "teacher_usage")
+	}
+
+	teachers, err := findTeachers(dbConn, name)
+	if err != nil {
+		return errText(c, "err_role_request", escapeHTML(err.Error()))
+	}
+	switch len(teachers) {
+	case 0:
+		return t(c, "teacher_not_found", escapeHTML(name))
+	case 1:
+	default:
+		return t(c, "teacher_ambiguous", escapeHTML(strings.Join(teachers, "\n")))
+	}
+
+	if user := senderUser(c); roleOf(c) == db.RoleTeacher && user.TeacherName == teachers[0] {
+		return t(c, "role_already")
+	}
+
+	request := &db.RoleRequest{
+		UserID:      c.Sender().ID,
+		Role:        db.RoleTeacher,
+		TeacherName: teachers[0],
+	}
+	return submitRoleRequest(c, dbConn, cfg, request)
+}
+
+func findTeachers(dbConn *pg.DB, name string) ([]string, error) {
+	var teachers []string
+	err := dbConn.Model((*db.Schedule)(nil)).
+		ColumnExpr("DISTINCT teacher").
+		Where("teacher ILIKE ?", "%"+name+"%").
+		Limit(teacherMatchLimit).
+		Select(&teachers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find teachers: %w", err)
+	}
+
+	for _, teacher := range teachers {
+		if strings.EqualFold(teacher, name) {
+			return []string{teacher}, nil
+		}
+	}
+	sort.Strings(teachers)
+	return teachers, nil
+}
+
+func submitRoleRequest(c telebot.Context, dbConn *pg.DB, cfg Config, request *db.RoleRequest) string {
+	pending, err := dbConn.Model((*db.RoleRequest)(nil)).
+		Where("user_id = ?", request.UserID).
+		Where("status = ?", db.RequestPending).
+		Exists()
+	if err != nil {
+		return errText(c, "err_role_request", escapeHTML(err.Error()))
+	}
+	if pending {
+		return t(c, "role_request_pending")
+	}
+
+	request.Status = db.RequestPending
+	request.CreatedAt = time.Now()
+	if _, err := dbConn.Model(request).Insert(); err != nil {
+		return errText(c, "err_role_request", escapeHTML(err.Error()))
+	}
+
+	text := tr(defaultLang, "role_request_new", roleRequestText(defaultLang, request, c.Sender()))
+	for _, adminID := range cfg.AdminIDs {
+		if _, err := sendToUser(c.Bot(), dbConn, adminID, text, roleReviewButtons(defaultLang, request.ID)); err != nil {
+			fmt.Printf("Failed to notify admin %d about role request %d: %v\n", adminID, request.ID, err)
+		}
+	}
+
+	return t(c, "role_request_sent")
+}
+
+func roleRequestText(lang string, request *db.RoleRequest, sender *telebot.User) string {
+	who := strconv.FormatInt(request.UserID, 10)
+	if sender != nil && sender.Username != "" {
+		who += " (@" + sender.Username + ")"
+	}
+
+	subject := request.GroupName
+	if request.Role == db.RoleTeacher {
+		subject = request.TeacherName
+	}
+	return fmt.Sprintf("#%d %s — %s: %s", request.ID, escapeHTML(who), tr(lang, "role_"+request.Role), escapeHTML(subject))
+}
+
+func roleReviewButtons(lang string, requestID int64) *telebot.ReplyMarkup {
+	id := strconv.FormatInt(requestID, 10)
+	return createMenu(2,
+		createButton(tr(lang, "btn_approve"), "role_review", id+"_"+db.RequestApproved),
+		createButton(tr(lang, "btn_reject"), "role_review", id+"_"+db.RequestRejected),
+	)
+}
+
+func roleRequestsView(dbConn *pg.DB, lang string) (string, *telebot.ReplyMarkup) {
+	var requests []db.RoleRequest
+	err := dbConn.Model(&requests).
+		Where("status = ?", db.RequestPending).
+		Order("created_at").
+		Limit(roleRequestsLimit).
+		Select()
+	if err != nil {
+		return errTr(lang, "err_role_requests", escapeHTML(err.Error())), adminBackButtons(lang)
+	}
+	if len(requests) == 0 {
+		return tr(lang, "role_requests_empty"), adminBackButtons(lang)
+	}
+
+	var sb strings.Builder
+	var rows [][]telebot.Btn
+	sb.WriteString(bold(tr(lang, "role_requests_header")) + "\n\n")
+	for i := range requests {
+		id := strconv.FormatInt(requests[i].ID, 10)
+		sb.WriteString(roleRequestText(lang, &requests[i], nil) + "\n")
+		rows = append(rows, []telebot.Btn{
+			{Text: tr(lang, "btn_approve") + " #" + id, Unique: "role_review", Data: id + "_" + db.RequestApproved},
+			{Text: tr(lang, "btn_reject") + " #" + id, Unique: "role_review", Data: id + "_" + db.RequestRejected},
+		})
+	}
+	rows = append(rows, createButton(tr(lang, "btn_back"), "admin_menu", ""))
+	return sb.String(), createMenuRows(rows...)
+}
+
+func handleRoleReview(c telebot.Context, dbConn *pg.DB) error {
+	idStr, status, _ := strings.Cut(c.Data(), "_")
+	requestID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || (status != db.RequestApproved && status != db.RequestRejected) {
+		return c.Respond()
+	}
+
+	request, err := reviewRoleRequest(dbConn, requestID, c.Sender().ID, status)
+	if errors.Is(err, errRequestReviewed) {
+		return c.Respond(&telebot.CallbackResponse{Text: t(c, "role_request_reviewed"), ShowAlert: true})
+	}
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: errText(c, "err_role_review", err.Error()), ShowAlert: true})
+	}
+
+	lang := userLangByID(dbConn, request.UserID)
+	if _, err := sendToUser(c.Bot(), dbConn, request.UserID, tr(lang, "role_request_"+status, tr(lang, "role_"+request.Role))); err != nil {
+		fmt.Printf("Failed to notify user %d about role request %d: %v\n", request.UserID, request.ID, err)
+	}
+
+	if err := c.Respond(&telebot.CallbackResponse{Text: t(c, "role_review_done")}); err != nil {
+		fmt.Printf("Failed to respond to callback: %v\n", err)
+	}
+	text, markup := roleRequestsView(dbConn, langOf(c))
+	return c.Edit(text, markup)
+}
+
+var errRequestReviewed = errors.New("role request already reviewed")
+
+func reviewRoleRequest(dbConn *pg.DB, requestID, adminID int64, status string) (*db.RoleRequest, error) {
+	request := &db.RoleRequest{ID: requestID}
+
+	err := dbConn.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		if err := tx.Model(request).WherePK().For("UPDATE").Select(); err != nil {
+			return err
+		}
+		if request.Status != db.RequestPending {
+			return errRequestReviewed
+		}
+
+		request.Status = status
+		request.ReviewedBy = adminID
+		request.ReviewedAt = time.Now()
+		if _, err := tx.Model(request).Column("status", "reviewed_by", "reviewed_at").WherePK().Update(); err != nil {
+			return err
+		}
+		if status != db.RequestApproved {
+			return nil
+		}
+
+		user := &db.Users{
+			TelegramID:  request.UserID,
+			Role:        request.Role,
+			RoleGroup:   request.GroupName,
+			TeacherName: request.TeacherName,
+		}
+		_, err := tx.Model(user).
+			OnConflict("(telegram_id) DO UPDATE").
+			Set("role = EXCLUDED.role").
+			Set("role_group = EXCLUDED.role_group").
+			Set("teacher_name = EXCLUDED.teacher_name").
+			Insert()
+		return err
+	})
+	return request, err
+}
+
+func groupMembers(dbConn *pg.DB, groupName string, exceptID int64) ([]db.Users, error) {
+	var users []db.Users
+	err := dbConn.Model(&users).
+		WhereGroup(func(q *pg.Query) (*pg.Query, error) {
+			return q.Where("group_name = ?", groupName).WhereOr("? = ANY(groups)", groupName), nil
+		}).
+		Where("telegram_id <> ?", exceptID).
+		Where("blocked_at IS NULL").
+		Select()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	return users, nil
+}
+
+func sendAnnouncement(bot *telebot.Bot, dbConn *pg.DB, recipients []db.Users, groupName, text string) {
+	for _, user := range recipients {
+		message := tr(userLang(&user), "announcement", escapeHTML(groupName)) + "\n\n" + escapeHTML(text)
+		if _, err := sendToUser(bot, dbConn, user.TelegramID, message); err != nil {
+			fmt.Printf("Failed to send announcement to %d: %v\n", user.TelegramID, err)
+		}
+	}
+}
+
+func sendTeacherWeek(c telebot.Context, dbConn *pg.DB, day time.Time, page int) error {
+	user := senderUser(c)
+	schedules, monday, err := getTeacherSchedule(dbConn, user.TeacherName, day)
+	if err != nil {
+		return editOrReplace(c, errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+
+	lang := langOf(c)
+	header := bold(user.TeacherName) + " — " + tr(lang, "week_from", monday.Format("02.01")) + "\n"
+	pages := []string{header + tr(lang, "no_week_schedule")}
+	if len(schedules) > 0 {
+		format := weekFormat(user.ViewPrefs, lang)
+		format.showGroups = true
+		format.hideTeacher = true
+		pages = dayPages(header, weekDays(schedules, format), lang)
+	}
+	if page < 0 || page >= len(pages) {
+		page = 0
+	}
+	trackEvent(c, db.EventView, "teacher_week", "")
+
+	date := monday.Format("02.01.2006")
+	return editOrReplace(c, pages[page], createMenuRows(
+		[]telebot.Btn{
+			{Text: "<<", Unique: "teacher_week", Data: viewData(monday.AddDate(0, 0, -7).Format("02.01.2006"), "")},
+			{Text: "●", Unique: "teacher_week", Data: viewData("", "")},
+			{Text: ">>", Unique: "teacher_week", Data: viewData(monday.AddDate(0, 0, 7).Format("02.01.2006"), "")},
+		},
+		pageButtons("teacher_week", date, "", page, len(pages)),
+		createButton(tr(lang, "btn_back"), "back", ""),
+	))
+}
+
+func getTeacherSchedule(dbConn *pg.DB, teacher string, day time.Time) ([]db.Schedule, time.Time, error) {
+	monday := day
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, -1)
+	}
+
+	var weekly []db.Schedule
+	for i := 0; i < 7; i++ {
+		date := monday.AddDate(0, 0, i)
+
+		var schedules []db.Schedule
+		err := dbConn.Model(&schedules).
+			Where("teacher = ?", teacher).
+			Where("lesson_date = ?", date.Format("02.01")).
+			Select()
+		if err != nil {
+			return nil, monday, fmt.Errorf("failed to get schedule: %w", err)
+		}
+
+		// Every lesson here is on date, so its year needs no resolving.
+		sort.SliceStable(schedules, func(i, j int) bool {
+			startI, _, _ := schedules[i].Period(date.Year(), date.Location())
+			startJ, _, _ := schedules[j].Period(date.Year(), date.Location())
+			return startI.Before(startJ)
+		})
+		weekly = append(weekly, schedules...)
+	}
+
+	return weekly, monday, nil
+}
+
+func adminMenuButtons(lang string) *telebot.ReplyMarkup {
+	return createMenu(1,
+		createButton(tr(lang, "btn_role_requests"), "role_requests", ""),
+		createButton(tr(lang, "btn_modlog"), "modlog", ""),
+		createButton(tr(lang, "btn_back"), "back", ""),
+	)
+}
+
+func adminBackButtons(lang string) *telebot.ReplyMarkup {
+	return createMenu(1,
+		createButton(tr(lang, "btn_back"), "admin_menu", ""),
+	)
+}
+
+func adminStatsText(c telebot.Context, dbConn *pg.DB) string {
+	now := time.Now()
+	counts := []struct {
+		key   string
+		query func(q *pg.Query) *pg.Query
+	}{
+		{"stats_users", func(q *pg.Query) *pg.Query { return q }},
+		{"stats_active", func(q *pg.Query) *pg.Query { return q.Where("blocked_at IS NULL") }},
+		{"stats_blocked", func(q *pg.Query) *pg.Query { return q.Where("blocked_at IS NOT NULL") }},
+		{"stats_with_group", func(q *pg.Query) *pg.Query { return q.Where("group_name <> ''") }},
+		{"stats_headmen", func(q *pg.Query) *pg.Query { return q.Where("role = ?", db.RoleHeadman) }},
+		{"stats_teachers", func(q *pg.Query) *pg.Query { return q.Where("role = ?", db.RoleTeacher) }},
+		{"stats_banned", func(q *pg.Query) *pg.Query {
+			return q.Where("is_banned").Where("banned_until IS NULL OR banned_until > ?", now)
+		}},
+	}
+
+	var sb strings.Builder
+	sb.WriteString(bold(t(c, "stats_header")) + "\n\n")
+	for _, count := range counts {
+		n, err := count.query(dbConn.Model((*db.Users)(nil))).Count()
+		if err != nil {
+			return errText(c, "err_stats", escapeHTML(err.Error()))
+		}
+		sb.WriteString(t(c, count.key, n) + "\n")
+	}
+
+	pending, err := dbConn.Model((*db.RoleRequest)(nil)).Where("status = ?", db.RequestPending).Count()
+	if err != nil {
+		return errText(c, "err_stats", escapeHTML(err.Error()))
+	}
+	sb.WriteString(t(c, "stats_role_requests", pending) + "\n\n")
+
+	usage, err := usageStatsText(dbConn, langOf(c), now)
+	if err != nil {
+		return errText(c, "err_stats", escapeHTML(err.Error()))
+	}
+	sb.WriteString(usage)
+
+	return sb.String()
+}
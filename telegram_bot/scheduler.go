@@ -0,0 +1,27 @@
+package telegram_bot
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+func runScheduler(bot *telebot.Bot, dbConn *pg.DB, adminChats []int64, updates <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	postDailySchedules(bot, dbConn, adminChats, time.Now())
+	publishWeeklyChannels(bot, dbConn, time.Now())
+
+	for {
+		select {
+		case <-ticker.C:
+			postDailySchedules(bot, dbConn, adminChats, time.Now())
+			publishWeeklyChannels(bot, dbConn, time.Now())
+		case <-updates:
+			refreshDailySchedules(bot, dbConn, adminChats, time.Now())
+			notifyChannelChanges(bot, dbConn, time.Now())
+		}
+	}
+}
@@ -4,16 +4,21 @@ import (
 	"fmt"
 	"math/rand"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/Ah3ron/schedule-bot/db"
 	"github.com/go-pg/pg/v10"
 	"gopkg.in/telebot.v3"
 )
 
+const maxSavedGroups = 5
+
 func createButton(text, unique, data string) []telebot.Btn {
 	return []telebot.Btn{
 		{Text: text, Unique: unique, Data: data},
@@ -43,23 +48,41 @@ func createMenu(buttonsInRow int, buttonGroups ...[]telebot.Btn) *telebot.ReplyM
 	return menu
 }
 
-func mainMenuButtons() *telebot.ReplyMarkup {
-	return createMenu(1,
-		createButton("📆 Расписание", "schedule", ""),
-		createButton("⚙️ Настройки", "settings", ""),
-		createButton("ℹ️ Информация", "information", ""),
-	)
+func createMenuRows(rows ...[]telebot.Btn) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	var menuRows []telebot.Row
+
+	for _, row := range rows {
+		if len(row) > 0 {
+			menuRows = append(menuRows, menu.Row(row...))
+		}
+	}
+
+	menu.Inline(menuRows...)
+	return menu
 }
 
-func scheduleMenuButtons() *telebot.ReplyMarkup {
+func mainMenuButtons(lang, role string) *telebot.ReplyMarkup {
+	buttons := [][]telebot.Btn{
+		createButton(tr(lang, "btn_schedule"), "schedule", ""),
+		createButton(tr(lang, "btn_settings"), "settings", ""),
+	}
+	buttons = append(buttons, roleMenuButtons(lang, role)...)
+	buttons = append(buttons, createButton(tr(lang, "btn_information"), "information", ""))
+	return createMenu(1, buttons...)
+}
+
+func scheduleMenuButtons(lang string) *telebot.ReplyMarkup {
 	return createMenu(1,
-		createButton("📆 На день", "now", ""),
-		createButton("📅 На неделю", "week", ""),
-		createButton("⬅️ Назад", "back", ""),
+		createButton(tr(lang, "btn_day"), "now", ""),
+		createButton(tr(lang, "btn_week"), "week", ""),
+		createButton(tr(lang, "btn_export_ics"), "export_ics", ""),
+		createButton(tr(lang, "btn_export_pdf"), "export_pdf", ""),
+		createButton(tr(lang, "btn_back"), "back", ""),
 	)
 }
 
-func scheduleNowMenuButtons(currentDay time.Time) *telebot.ReplyMarkup {
+func scheduleNowMenuButtons(currentDay time.Time, viewer scheduleViewer) *telebot.ReplyMarkup {
 	currentMonday := currentDay
 	for currentMonday.Weekday() != time.Monday {
 		currentMonday = currentMonday.AddDate(0, 0, -1)
@@ -71,17 +94,22 @@ func scheduleNowMenuButtons(currentDay time.Time) *telebot.ReplyMarkup {
 	previousDay := currentDay.AddDate(0, 0, -1)
 	nextDay := currentDay.AddDate(0, 0, 1)
 
-	return createMenu(5,
-		createButton("<<", "now", previousMonday.Format("02.01.2006")),
-		createButton("<", "now", previousDay.Format("02.01.2006")),
-		createButton("●", "now", ""),
-		createButton(">", "now", nextDay.Format("02.01.2006")),
-		createButton(">>", "now", nextMonday.Format("02.01.2006")),
-		createButton("⬅️ Назад", "back", ""),
+	group := viewer.groupName
+	return createMenuRows(
+		[]telebot.Btn{
+			{Text: "<<", Unique: "now", Data: viewData(previousMonday.Format("02.01.2006"), group)},
+			{Text: "<", Unique: "now", Data: viewData(previousDay.Format("02.01.2006"), group)},
+			{Text: "●", Unique: "now", Data: viewData("", group)},
+			{Text: ">", Unique: "now", Data: viewData(nextDay.Format("02.01.2006"), group)},
+			{Text: ">>", Unique: "now", Data: viewData(nextMonday.Format("02.01.2006"), group)},
+		},
+		groupSwitcherButtons("now", currentDay.Format("02.01.2006"), viewer),
+		reportButton("day", currentDay, viewer),
+		viewerBackButton(viewer),
 	)
 }
 
-func scheduleWeekMenuButtons(currentDay time.Time) *telebot.ReplyMarkup {
+func scheduleWeekMenuButtons(currentDay time.Time, viewer scheduleViewer, page, pages int) *telebot.ReplyMarkup {
 	currentMonday := currentDay
 	for currentMonday.Weekday() != time.Monday {
 		currentMonday = currentMonday.AddDate(0, 0, -1)
@@ -90,31 +118,75 @@ func scheduleWeekMenuButtons(currentDay time.Time) *telebot.ReplyMarkup {
 	previousMonday := currentMonday.AddDate(0, 0, -7)
 	nextMonday := currentMonday.AddDate(0, 0, 7)
 
-	return createMenu(3,
-		createButton("<<", "week", previousMonday.Format("02.01.2006")),
-		createButton("●", "week", ""),
-		createButton(">>", "week", nextMonday.Format("02.01.2006")),
-		createButton("⬅️ Назад", "back", ""),
+	group := viewer.groupName
+	return createMenuRows(
+		[]telebot.Btn{
+			{Text: "<<", Unique: "week", Data: viewData(previousMonday.Format("02.01.2006"), group)},
+			{Text: "●", Unique: "week", Data: viewData("", group)},
+			{Text: ">>", Unique: "week", Data: viewData(nextMonday.Format("02.01.2006"), group)},
+		},
+		pageButtons("week", currentMonday.Format("02.01.2006"), group, page, pages),
+		groupSwitcherButtons("week", currentMonday.Format("02.01.2006"), viewer),
+		reportButton("week", currentMonday, viewer),
+		viewerBackButton(viewer),
 	)
 }
 
-func settingsMenuButtons() *telebot.ReplyMarkup {
-	return createMenu(1,
-		createButton("🔄 Выбрать группу", "choose_group", ""),
-		createButton("⬅️ Назад", "back", ""),
-	)
+func groupSwitcherButtons(unique, date string, viewer scheduleViewer) []telebot.Btn {
+	if len(viewer.groups) < 2 {
+		return nil
+	}
+
+	var buttons []telebot.Btn
+	for _, group := range viewer.groups {
+		text := group
+		if group == viewer.groupName {
+			text = "✓ " + group
+		}
+		buttons = append(buttons, telebot.Btn{Text: text, Unique: unique, Data: viewData(date, group)})
+	}
+	return buttons
+}
+
+func viewerBackButton(viewer scheduleViewer) []telebot.Btn {
+	if viewer.inGroupChat {
+		return nil
+	}
+	return createButton(tr(viewer.lang, "btn_back"), "back", "")
+}
+
+func viewData(date, group string) string {
+	return date + "_" + group
+}
+
+func pageData(date, group string, page int) string {
+	return viewData(date, group) + "_" + strconv.Itoa(page)
 }
 
-func backMenuButtons() *telebot.ReplyMarkup {
+func parseViewData(data string) (date, group string, page int) {
+	date, rest, _ := strings.Cut(data, "_")
+	group, pageStr, _ := strings.Cut(rest, "_")
+	page, _ = strconv.Atoi(pageStr)
+	return date, group, page
+}
+
+func settingsMenuButtons(lang string) *telebot.ReplyMarkup {
 	return createMenu(1,
-		createButton("⬅️ Назад", "back", ""),
+		createButton(tr(lang, "btn_choose_group"), "choose_group", ""),
+		createButton(tr(lang, "btn_my_groups"), "my_groups", ""),
+		createButton(tr(lang, "btn_subgroup"), "choose_subgroup", ""),
+		createButton(tr(lang, "btn_view_prefs"), "view_prefs", ""),
+		createButton(tr(lang, "btn_week_view"), "week_view", ""),
+		createButton(tr(lang, "btn_ics_feed"), "ics_feed", ""),
+		createButton(tr(lang, "btn_language"), "language", ""),
+		createButton(tr(lang, "btn_role"), "role", ""),
+		createButton(tr(lang, "btn_back"), "back", ""),
 	)
 }
 
-func termsOfServiceButtons() *telebot.ReplyMarkup {
-	return createMenu(2,
-		createButton("Принять", "accept_terms", ""),
-		createButton("Отказаться", "decline_terms", ""),
+func backMenuButtons(lang string) *telebot.ReplyMarkup {
+	return createMenu(1,
+		createButton(tr(lang, "btn_back"), "back", ""),
 	)
 }
 
@@ -188,20 +260,11 @@ func getGroups(groups []string, year, spec string) []string {
 }
 
 func handleCommands(bot *telebot.Bot, dbConn *pg.DB) {
-	bot.Handle("/start", func(c telebot.Context) error {
-		return c.Send("*Отказ от ответственности*\n\nИнформация, предоставляемая ботом, носит справочный характер. Мы не несем ответственности за точность, полноту или актуальность данных. Использование информации осуществляется на ваш собственный риск.\n\nНажмите кнопку ниже, чтобы принять правила:", termsOfServiceButtons())
-	})
-
-	bot.Handle(&telebot.Btn{Unique: "accept_terms"}, func(c telebot.Context) error {
-		return c.Edit("Благодарим вас за принятие условий предоставления услуг. Добро пожаловать в бот!", mainMenuButtons())
-	})
-
-	bot.Handle(&telebot.Btn{Unique: "decline_terms"}, func(c telebot.Context) error {
-		return c.Edit("Чтобы использовать этого бота, вам необходимо принять условия предоставления услуг")
-	})
+	personal := bot.Group()
+	personal.Use(privateOnly)
 
-	bot.Handle(&telebot.Btn{Unique: "schedule"}, func(c telebot.Context) error {
-		return c.Edit("Меню расписания:", scheduleMenuButtons())
+	personal.Handle(&telebot.Btn{Unique: "schedule"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "schedule_menu"), scheduleMenuButtons(langOf(c)))
 	})
 
 	bot.Handle(&telebot.Btn{Unique: "now"}, func(c telebot.Context) error {
@@ -212,96 +275,210 @@ func handleCommands(bot *telebot.Bot, dbConn *pg.DB) {
 		return handleWeekButton(c, dbConn)
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "back"}, func(c telebot.Context) error {
-		return c.Edit("Главное меню:", mainMenuButtons())
+	personal.Handle(&telebot.Btn{Unique: "back"}, func(c telebot.Context) error {
+		return editOrReplace(c, t(c, "main_menu"), mainMenuButtons(langOf(c), roleOf(c)))
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "settings"}, func(c telebot.Context) error {
-		return c.Edit("Настройки:", settingsMenuButtons())
+	personal.Handle(&telebot.Btn{Unique: "settings"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "settings_menu"), settingsMenuButtons(langOf(c)))
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "choose_group"}, func(c telebot.Context) error {
+	personal.Handle(&telebot.Btn{Unique: "choose_group"}, func(c telebot.Context) error {
 		return handleChooseGroup(c, dbConn)
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "select_year"}, func(c telebot.Context) error {
+	personal.Handle(&telebot.Btn{Unique: "select_year"}, func(c telebot.Context) error {
 		return handleSelectYear(c, dbConn)
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "select_spec"}, func(c telebot.Context) error {
+	personal.Handle(&telebot.Btn{Unique: "select_spec"}, func(c telebot.Context) error {
 		return handleSelectSpec(c, dbConn)
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "select_group"}, func(c telebot.Context) error {
+	personal.Handle(&telebot.Btn{Unique: "select_group"}, func(c telebot.Context) error {
 		return handleSelectGroup(c, dbConn)
 	})
 
-	bot.Handle(&telebot.Btn{Unique: "information"}, func(c telebot.Context) error {
-		return c.Edit("Информация:", backMenuButtons())
+	personal.Handle(&telebot.Btn{Unique: "my_groups"}, func(c telebot.Context) error {
+		return handleMyGroups(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "primary_group"}, func(c telebot.Context) error {
+		return handleSetPrimaryGroup(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "remove_group"}, func(c telebot.Context) error {
+		return handleRemoveGroup(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "choose_subgroup"}, func(c telebot.Context) error {
+		return handleChooseSubgroup(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "select_subgroup"}, func(c telebot.Context) error {
+		return handleSelectSubgroup(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "view_prefs"}, func(c telebot.Context) error {
+		return handleViewPrefs(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "week_view"}, func(c telebot.Context) error {
+		return handleWeekViewSetting(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "language"}, func(c telebot.Context) error {
+		return handleLanguage(c, dbConn)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "information"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "information"), backMenuButtons(langOf(c)))
 	})
 }
 
+type scheduleViewer struct {
+	groupName   string
+	subgroup    string
+	groups      []string
+	isBanned    bool
+	inGroupChat bool
+	weekImage   bool
+	prefs       db.ViewPrefs
+	lang        string
+}
+
+func userViewer(user *db.Users, groupName string) scheduleViewer {
+	viewer := scheduleViewer{
+		groupName: user.GroupName,
+		subgroup:  user.SubgroupOf(user.GroupName),
+		groups:    user.Groups,
+		isBanned:  user.IsBannedAt(time.Now()),
+		weekImage: user.WeekView == weekViewImage,
+		prefs:     user.ViewPrefs,
+		lang:      defaultLang,
+	}
+
+	if groupName != "" && groupName != user.GroupName {
+		viewer.groupName = groupName
+		viewer.subgroup = user.SubgroupOf(groupName)
+	}
+
+	return viewer
+}
+
 func handleNowButton(c telebot.Context, dbConn *pg.DB) error {
-	userID := c.Sender().ID
+	defer trackLatency("day", time.Now())
+	date, group, _ := parseViewData(c.Data())
 
-	user, err := getUserInfo(dbConn, userID)
+	viewer, err := resolveViewer(c, dbConn, group)
 	if err != nil {
-		return c.Edit("Вы не выбрали группу для просмотра расписания.", backMenuButtons())
+		if isGroupChat(c.Chat()) {
+			return c.Edit(noGroupText(c))
+		}
+		return c.Edit(t(c, "no_group_selected"), backMenuButtons(langOf(c)))
 	}
 
-	todayTime, todayStr, err := parseDate(c.Data())
+	todayTime, _, err := parseDate(date)
 	if err != nil {
 		todayTime = time.Now()
-		todayStr = todayTime.Format("02.01.2006")
 	}
 
-	schedules, err := getSchedule(dbConn, user.GroupName, todayTime)
+	text, err := dayScheduleText(dbConn, viewer, todayTime)
+	if err != nil {
+		return c.Edit(errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+	trackEvent(c, db.EventView, "day", viewer.groupName)
+
+	return c.Edit(text, scheduleNowMenuButtons(todayTime, viewer))
+}
+
+func dayScheduleText(dbConn *pg.DB, viewer scheduleViewer, day time.Time) (string, error) {
+	schedules, err := getSchedule(dbConn, viewer.groupName, day)
 	if err != nil {
-		return c.Edit(fmt.Sprintf("Ошибка получения расписания: %v", err))
+		return "", err
 	}
+	schedules = filterBySubgroup(schedules, viewer.subgroup)
 	if len(schedules) == 0 {
-		return c.Edit(fmt.Sprintf("Расписание не найдено на дату %s", todayStr[:5]), scheduleNowMenuButtons(todayTime))
+		return viewerHeader(viewer) + tr(viewer.lang, "no_day_schedule", dayMonth(viewer.lang, day)), nil
 	}
 
-	text := formatSchedule(schedules, todayTime)
+	text := viewerHeader(viewer) + formatSchedule(schedules, day, viewer.prefs, viewer.lang)
 
-	if user.IsBanned {
-		text = shuffleString(text)
+	return viewer.obfuscate(text), nil
+}
+
+func (viewer scheduleViewer) obfuscate(text string) string {
+	if viewer.isBanned {
+		return shuffleString(text)
 	}
+	return text
+}
 
-	return c.Edit(text, scheduleNowMenuButtons(todayTime))
+func viewerHeader(viewer scheduleViewer) string {
+	if len(viewer.groups) < 2 && !viewer.inGroupChat {
+		return ""
+	}
+	return groupHeader(viewer.lang, viewer.groupName)
 }
 
 func shuffleString(s string) string {
-	runes := []rune(s)
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
 	var letters []rune
-	for _, r := range runes {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			letters = append(letters, r)
+	forEachTextRune(s, func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			letters = append(letters, c)
 		}
-	}
+		return c
+	})
 
 	for i := range letters {
 		j := r.Intn(len(letters))
 		letters[i], letters[j] = letters[j], letters[i]
 	}
 
-	var result strings.Builder
 	lettersIndex := 0
-	for _, r := range runes {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			result.WriteRune(letters[lettersIndex])
+	return forEachTextRune(s, func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			c = letters[lettersIndex]
 			lettersIndex++
-		} else {
-			result.WriteRune(r)
 		}
-	}
+		return c
+	})
+}
 
+func forEachTextRune(s string, f func(rune) rune) string {
+	var result strings.Builder
+	for i := 0; i < len(s); {
+		if end := markupEnd(s, i); end > i {
+			result.WriteString(s[i:end])
+			i = end
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		result.WriteRune(f(c))
+		i += size
+	}
 	return result.String()
 }
 
+func markupEnd(s string, i int) int {
+	var end int
+	switch s[i] {
+	case '<':
+		end = strings.IndexByte(s[i:], '>')
+	case '&':
+		end = strings.IndexByte(s[i:], ';')
+	default:
+		return i
+	}
+	if end < 0 {
+		return i
+	}
+	return i + end + 1
+}
+
 func parseDate(dateStr string) (time.Time, string, error) {
 	if dateStr == "" {
 		t := time.Now()
@@ -315,41 +492,44 @@ func parseDate(dateStr string) (time.Time, string, error) {
 	return t, dateStr, nil
 }
 
-func formatSchedule(schedules []db.Schedule, todayTime time.Time) string {
-	var text strings.Builder
-	todayStr := todayTime.Format("02.01")
-	text.WriteString(fmt.Sprintf("Ваше расписание (%s, %s)\n", schedules[0].DayOfWeek, todayStr))
-
-	for _, schedule := range schedules {
-		text.WriteString(fmt.Sprintf("\n*Время:* _%s_\n*Пара:* _%s_", schedule.LessonTime, schedule.LessonName))
-		if schedule.Location != "" {
-			text.WriteString(fmt.Sprintf("\n*Аудит.:* _%s_", schedule.Location))
-		}
-		if schedule.Teacher != "" {
-			text.WriteString(fmt.Sprintf("\n*Препод.:* _%s_", schedule.Teacher))
-		}
-		if schedule.Subgroup != "" {
-			text.WriteString(fmt.Sprintf("\n*Подгруппа:* _%s_", schedule.Subgroup))
-		}
-		text.WriteString("\n")
-	}
-	return text.String()
+func formatSchedule(schedules []db.Schedule, todayTime time.Time, prefs db.ViewPrefs, lang string) string {
+	return renderTemplate(lang, dayTemplate, dayView{
+		Weekday: localDayOfWeek(lang, schedules[0].DayOfWeek),
+		Date:    dayMonth(lang, todayTime),
+		Lessons: lessonViews(schedules, dayFormat(prefs, lang)),
+		L:       catalog(lang),
+	})
 }
 
 func handleWeekButton(c telebot.Context, dbConn *pg.DB) error {
-	userID := c.Sender().ID
+	defer trackLatency("week", time.Now())
+	date, group, page := parseViewData(c.Data())
 
-	user, err := getUserInfo(dbConn, userID)
+	viewer, err := resolveViewer(c, dbConn, group)
 	if err != nil {
-		return c.Edit("Вы не выбрали группу для просмотра расписания.", backMenuButtons())
+		if isGroupChat(c.Chat()) {
+			return editOrReplace(c, noGroupText(c))
+		}
+		return editOrReplace(c, t(c, "no_group_selected"), backMenuButtons(langOf(c)))
 	}
 
-	todayTime, _, err := parseDate(c.Data())
+	todayTime, _, err := parseDate(date)
 	if err != nil {
 		todayTime = time.Now()
 	}
 
-	currentMonday := todayTime
+	weeklySchedules, currentMonday, err := getWeeklySchedule(dbConn, viewer.groupName, viewer.subgroup, todayTime)
+	if err != nil {
+		return editOrReplace(c, errText(c, "err_schedule", escapeHTML(err.Error())))
+	}
+
+	what, page, pages := weekScheduleView(viewer, weeklySchedules, currentMonday, page)
+	trackEvent(c, db.EventView, "week", viewer.groupName)
+	return editOrReplace(c, what, scheduleWeekMenuButtons(currentMonday, viewer, page, pages))
+}
+
+func getWeeklySchedule(dbConn *pg.DB, groupName, subgroup string, day time.Time) ([]db.Schedule, time.Time, error) {
+	currentMonday := day
 	for currentMonday.Weekday() != time.Monday {
 		currentMonday = currentMonday.AddDate(0, 0, -1)
 	}
@@ -357,74 +537,29 @@ func handleWeekButton(c telebot.Context, dbConn *pg.DB) error {
 	var weeklySchedules []db.Schedule
 
 	for i := 0; i < 7; i++ {
-		day := currentMonday.AddDate(0, 0, i)
-		schedules, err := getSchedule(dbConn, user.GroupName, day)
+		schedules, err := getSchedule(dbConn, groupName, currentMonday.AddDate(0, 0, i))
 		if err != nil {
-			return c.Edit(fmt.Sprintf("Ошибка получения расписания: %v", err))
+			return nil, currentMonday, err
 		}
-		weeklySchedules = append(weeklySchedules, schedules...)
-	}
-
-	if len(weeklySchedules) == 0 {
-		return c.Edit("Расписание не найдено на эту неделю.", scheduleMenuButtons())
+		weeklySchedules = append(weeklySchedules, filterBySubgroup(schedules, subgroup)...)
 	}
 
-	text := formatWeeklySchedule(weeklySchedules)
-
-	return c.Edit(text, scheduleWeekMenuButtons(currentMonday))
+	return weeklySchedules, currentMonday, nil
 }
 
-func formatWeeklySchedule(schedules []db.Schedule) string {
-	var text strings.Builder
-	var DayOfWeek string
-
-	for _, schedule := range schedules {
-		if DayOfWeek != schedule.DayOfWeek {
-			DayOfWeek = schedule.DayOfWeek
-			text.WriteString("\n")
-			text.WriteString(fmt.Sprintf("*%s* (%s):\n\n", DayOfWeek, schedule.LessonDate))
-		}
-
-		timeParts := strings.Split(schedule.LessonTime, "-")
-		if len(timeParts) > 0 {
-			schedule.LessonTime = strings.TrimSpace(timeParts[0])
-		}
-
-		text.WriteString(fmt.Sprintf("*%s*: _%s_", schedule.LessonTime, schedule.LessonName))
-		if schedule.Location != "" {
-			text.WriteString(fmt.Sprintf("; _%s_", schedule.Location))
-		}
-		if schedule.Teacher != "" {
-			text.WriteString(fmt.Sprintf("; _%s_", formatTeacherName(schedule.Teacher)))
-		}
-		if schedule.Subgroup != "" {
-			text.WriteString(fmt.Sprintf(" (_%s_)", schedule.Subgroup))
-		}
-		text.WriteString("\n")
-	}
-
-	return text.String()
-}
-
-func formatTeacherName(fullName string) string {
-	parts := strings.Fields(strings.TrimSpace(fullName))
-
-	if len(parts) < 3 {
-		return fullName
-	}
-
-	return fmt.Sprintf("%s %c. %c.", parts[0], []rune(parts[1])[0], []rune(parts[2])[0])
+func formatWeeklySchedule(schedules []db.Schedule, prefs db.ViewPrefs, lang string) string {
+	return renderTemplate(lang, weekTemplate, weekDays(schedules, weekFormat(prefs, lang)))
 }
 
 func handleChooseGroup(c telebot.Context, dbConn *pg.DB) error {
 	uniqueGroups, err := getUniqueGroups(dbConn)
 	if err != nil {
-		return c.Edit(fmt.Sprintf("Ошибка получения групп: %v", err))
+		return c.Edit(errText(c, "err_groups", escapeHTML(err.Error())))
 	}
 
 	years := getAdmissionYears(uniqueGroups)
 	yearButtons := createYearButtons(years)
-	return c.Edit("Выберите год поступления:", yearButtons)
+	return c.Edit(t(c, "choose_year"), yearButtons)
 }
 
 func createYearButtons(years []string) *telebot.ReplyMarkup {
@@ -440,12 +575,12 @@ func handleSelectYear(c telebot.Context, dbConn *pg.DB) error {
 	selectedYear := c.Data()
 	uniqueGroups, err := getUniqueGroups(dbConn)
 	if err != nil {
-		return c.Edit(fmt.Sprintf("Ошибка получения групп: %v", err))
+		return c.Edit(errText(c, "err_groups", escapeHTML(err.Error())))
 	}
 
 	specs := getSpecializations(uniqueGroups, selectedYear)
 	specButtons := createSpecButtons(specs, selectedYear)
-	return c.Edit("Выберите поток:", specButtons)
+	return c.Edit(t(c, "choose_spec"), specButtons)
 }
 
 func createSpecButtons(specs []string, selectedYear string) *telebot.ReplyMarkup {
@@ -460,18 +595,18 @@ func createSpecButtons(specs []string, selectedYear string) *telebot.ReplyMarkup
 func handleSelectSpec(c telebot.Context, dbConn *pg.DB) error {
 	data := strings.Split(c.Data(), "_")
 	if len(data) < 2 {
-		return c.Edit("Ошибка: некорректные данные для специальности.")
+		return c.Edit(errText(c, "err_spec_data"))
 	}
 	selectedYear, selectedSpec := data[0], data[1]
 
 	uniqueGroups, err := getUniqueGroups(dbConn)
 	if err != nil {
-		return c.Edit(fmt.Sprintf("Ошибка получения групп: %v", err))
+		return c.Edit(errText(c, "err_groups", escapeHTML(err.Error())))
 	}
 
 	groups := getGroups(uniqueGroups, selectedYear, selectedSpec)
 	groupButtons := createGroupButtons(groups)
-	return c.Edit("Выберите группу:", groupButtons)
+	return c.Edit(t(c, "choose_group"), groupButtons)
 }
 
 func createGroupButtons(groups []string) *telebot.ReplyMarkup {
@@ -485,20 +620,208 @@ func createGroupButtons(groups []string) *telebot.ReplyMarkup {
 
 func handleSelectGroup(c telebot.Context, dbConn *pg.DB) error {
 	selectedGroup := c.Data()
+	if err := saveUserGroup(dbConn, c.Sender().ID, selectedGroup); err != nil {
+		return c.Edit(errText(c, "err_save_group", escapeHTML(err.Error())))
+	}
+
+	return c.Edit(t(c, "group_selected", escapeHTML(selectedGroup)), mainMenuButtons(langOf(c), roleOf(c)))
+}
+
+func saveUserGroup(dbConn *pg.DB, userID int64, group string) error {
+	groups := []string{group}
+	if existing, err := getUserInfo(dbConn, userID); err == nil {
+		groups = addSavedGroup(existing.Groups, group)
+	}
+
 	user := &db.Users{
-		TelegramID: c.Sender().ID,
-		GroupName:  selectedGroup,
+		TelegramID: userID,
+		GroupName:  group,
+		Groups:     groups,
 	}
 
 	_, err := dbConn.Model(user).
 		OnConflict("(telegram_id) DO UPDATE").
 		Set("group_name = EXCLUDED.group_name").
+		Set("groups = EXCLUDED.groups").
 		Insert()
+	return err
+}
+
+func addSavedGroup(groups []string, group string) []string {
+	result := []string{group}
+	for _, g := range groups {
+		if g != group && len(result) < maxSavedGroups {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+func handleMyGroups(c telebot.Context, dbConn *pg.DB) error {
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil || len(user.Groups) == 0 {
+		return c.Edit(t(c, "no_saved_groups"), settingsMenuButtons(langOf(c)))
+	}
+
+	return c.Edit(t(c, "my_groups"), myGroupsButtons(user, langOf(c)))
+}
+
+func myGroupsButtons(user *db.Users, lang string) *telebot.ReplyMarkup {
+	var rows [][]telebot.Btn
+	for _, group := range user.Groups {
+		text := group
+		if group == user.GroupName {
+			text = "⭐ " + group
+		}
+		rows = append(rows, []telebot.Btn{
+			{Text: text, Unique: "primary_group", Data: group},
+			{Text: "✖", Unique: "remove_group", Data: group},
+		})
+	}
+
+	if len(user.Groups) < maxSavedGroups {
+		rows = append(rows, createButton(tr(lang, "btn_add_group"), "choose_group", ""))
+	}
+	rows = append(rows, createButton(tr(lang, "btn_back"), "settings", ""))
+
+	return createMenuRows(rows...)
+}
+
+func handleSetPrimaryGroup(c telebot.Context, dbConn *pg.DB) error {
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return c.Edit(errText(c, "err_groups", escapeHTML(err.Error())))
+	}
+
+	group := c.Data()
+	if !slices.Contains(user.Groups, group) {
+		return c.Edit(t(c, "group_not_saved"), myGroupsButtons(user, langOf(c)))
+	}
+
+	user.GroupName = group
+	if err := updateUserGroups(dbConn, user); err != nil {
+		return c.Edit(errText(c, "err_save_group", escapeHTML(err.Error())))
+	}
+
+	return c.Edit(t(c, "primary_group", escapeHTML(group)), myGroupsButtons(user, langOf(c)))
+}
+
+func handleRemoveGroup(c telebot.Context, dbConn *pg.DB) error {
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return c.Edit(errText(c, "err_groups", escapeHTML(err.Error())))
+	}
+
+	if len(user.Groups) < 2 {
+		return c.Respond(&telebot.CallbackResponse{Text: t(c, "cannot_remove_last")})
+	}
+
+	group := c.Data()
+	user.Groups = slices.DeleteFunc(user.Groups, func(g string) bool { return g == group })
+	delete(user.Subgroups, group)
+	if user.GroupName == group {
+		user.GroupName = user.Groups[0]
+	}
+
+	if err := updateUserGroups(dbConn, user); err != nil {
+		return c.Edit(errText(c, "err_save_group", escapeHTML(err.Error())))
+	}
+
+	return c.Edit(t(c, "group_removed", escapeHTML(group), escapeHTML(user.GroupName)), myGroupsButtons(user, langOf(c)))
+}
+
+func updateUserGroups(dbConn *pg.DB, user *db.Users) error {
+	_, err := dbConn.Model(user).
+		Column("group_name", "groups", "subgroups").
+		WherePK().
+		Update()
+	return err
+}
+
+func getGroupSubgroups(dbConn *pg.DB, groupName string) ([]string, error) {
+	var subgroups []string
+	err := dbConn.Model((*db.Schedule)(nil)).
+		ColumnExpr("DISTINCT subgroup").
+		Where("group_name = ?", groupName).
+		Where("COALESCE(subgroup, '') <> ''").
+		Order("subgroup").
+		Select(&subgroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subgroups: %w", err)
+	}
+	return subgroups, nil
+}
+
+func handleChooseSubgroup(c telebot.Context, dbConn *pg.DB) error {
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return c.Edit(t(c, "choose_group_first"), settingsMenuButtons(langOf(c)))
+	}
+
+	subgroups, err := getGroupSubgroups(dbConn, user.GroupName)
 	if err != nil {
-		return c.Edit(fmt.Sprintf("Ошибка сохранения группы: %v", err))
+		return c.Edit(errText(c, "err_subgroups", escapeHTML(err.Error())))
 	}
+	if len(subgroups) == 0 {
+		return c.Edit(t(c, "no_subgroups", escapeHTML(user.GroupName)), settingsMenuButtons(langOf(c)))
+	}
+
+	current := user.SubgroupOf(user.GroupName)
+	if current == "" {
+		current = t(c, "subgroup_all")
+	}
+
+	return c.Edit(t(c, "choose_subgroup", escapeHTML(current)), createSubgroupButtons(subgroups, langOf(c)))
+}
 
-	return c.Edit(fmt.Sprintf("Ваша группа была успешно выбрана: %s", selectedGroup), mainMenuButtons())
+func createSubgroupButtons(subgroups []string, lang string) *telebot.ReplyMarkup {
+	var subgroupButtons [][]telebot.Btn
+	for _, subgroup := range subgroups {
+		subgroupButtons = append(subgroupButtons, createButton(subgroup, "select_subgroup", subgroup))
+	}
+	subgroupButtons = append(subgroupButtons, createButton(tr(lang, "btn_all_subgroups"), "select_subgroup", ""))
+	return createMenu(1, subgroupButtons...)
+}
+
+func handleSelectSubgroup(c telebot.Context, dbConn *pg.DB) error {
+	selectedSubgroup := c.Data()
+
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return c.Edit(t(c, "choose_group_first"), settingsMenuButtons(langOf(c)))
+	}
+
+	if user.Subgroups == nil {
+		user.Subgroups = make(map[string]string)
+	}
+	if selectedSubgroup == "" {
+		delete(user.Subgroups, user.GroupName)
+	} else {
+		user.Subgroups[user.GroupName] = selectedSubgroup
+	}
+
+	if _, err := dbConn.Model(user).Column("subgroups").WherePK().Update(); err != nil {
+		return c.Edit(errText(c, "err_save_subgroup", escapeHTML(err.Error())))
+	}
+
+	if selectedSubgroup == "" {
+		return c.Edit(t(c, "subgroup_cleared"), settingsMenuButtons(langOf(c)))
+	}
+	return c.Edit(t(c, "subgroup_selected", escapeHTML(selectedSubgroup)), settingsMenuButtons(langOf(c)))
+}
+
+func filterBySubgroup(schedules []db.Schedule, subgroup string) []db.Schedule {
+	if subgroup == "" {
+		return schedules
+	}
+
+	filtered := make([]db.Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.AppliesTo(subgroup) {
+			filtered = append(filtered, schedule)
+		}
+	}
+	return filtered
 }
 
 func getUserInfo(dbConn *pg.DB, userID int64) (*db.Users, error) {
@@ -507,6 +830,9 @@ func getUserInfo(dbConn *pg.DB, userID int64) (*db.Users, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user info: %w", err)
 	}
+	if user.GroupName == "" {
+		return nil, fmt.Errorf("user %d has not chosen a group", userID)
+	}
 	return &user, nil
 }
 
@@ -519,16 +845,36 @@ func getSchedule(dbConn *pg.DB, groupName string, day time.Time) ([]db.Schedule,
 		Where("group_name = ?", groupName).
 		Where("lesson_date = ?", dayStr).
 		Select()
+	if err != nil {
+		recordEvent(db.Event{Kind: db.EventError, Name: "getSchedule", GroupName: groupName})
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return schedules, nil
+}
+
+func getGroupSchedules(dbConn *pg.DB, groupName string) ([]db.Schedule, error) {
+	var schedules []db.Schedule
+	err := dbConn.Model(&schedules).
+		Where("group_name = ?", groupName).
+		Select()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schedule: %w", err)
 	}
 	return schedules, nil
 }
 
-func Start(token string, dbConn *pg.DB) {
+type Config struct {
+	Token       string
+	FeedURL     string
+	AdminIDs    []int64
+	AdminChatID int64
+	BanMode     string
+}
+
+func Start(cfg Config, dbConn *pg.DB, updates <-chan struct{}) {
 	opts := telebot.Settings{
-		Token:     token,
-		ParseMode: "Markdown",
+		Token:     cfg.Token,
+		ParseMode: telebot.ModeHTML,
 		Poller: &telebot.LongPoller{
 			Timeout: 3 * time.Second,
 			AllowedUpdates: []string{
@@ -546,6 +892,33 @@ func Start(token string, dbConn *pg.DB) {
 		return
 	}
 
+	setCommands(bot)
+	go recordEvents(dbConn)
+
+	bot.Use(withSender(dbConn), withRole(cfg.AdminIDs), trackUpdates, enforceBan(cfg), requireTerms)
+
+	handleTerms(bot, dbConn)
 	handleCommands(bot, dbConn)
+	handleTextCommands(bot, dbConn)
+	handleGroupChats(bot, dbConn)
+	handleChannels(bot, dbConn)
+	handleInlineQueries(bot, dbConn)
+	handleExports(bot, dbConn, cfg.FeedURL)
+	handleModeration(bot, dbConn, cfg)
+	handleRoles(bot, dbConn, cfg)
+	broadcasts := make(chan struct{}, 1)
+	handleBroadcasts(bot, dbConn, broadcasts)
+	handleReports(bot, dbConn, cfg)
+
+	bot.Handle(telebot.OnText, func(c telebot.Context) error {
+		if handled, err := handleReportText(c, dbConn, cfg); handled {
+			return err
+		}
+		return handleTextQuery(c, dbConn)
+	})
+
+	go runScheduler(bot, dbConn, reportChats(cfg), updates)
+	go runBroadcasts(bot, dbConn, broadcasts)
+
 	bot.Start()
 }
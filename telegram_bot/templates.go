@@ -0,0 +1,224 @@
+package telegram_bot
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+var viewTemplates = template.Must(template.New("views").Parse(
+	`{{define "lesson"}}{{if .Detailed}}
+{{if .Emoji}}🕐{{else}}<b>{{.L.label_time}}</b>{{end}} <i>{{.Time}}</i>
+{{if .Emoji}}📖{{else}}<b>{{.L.label_lesson}}</b>{{end}} <i>{{.Name}}</i>
+{{- if .Room}}
+{{if .Emoji}}📍{{else}}<b>{{.L.label_room}}</b>{{end}} <i>{{.Room}}</i>{{end}}
+{{- if .Teacher}}
+{{if .Emoji}}👤{{else}}<b>{{.L.label_teacher}}</b>{{end}} <i>{{.Teacher}}</i>{{end}}
+{{- if .Group}}
+{{if .Emoji}}🎓{{else}}<b>{{.L.label_group}}</b>{{end}} <i>{{.Group}}</i>{{end}}
+{{- if .Subgroup}}
+{{if .Emoji}}👥{{else}}<b>{{.L.label_subgroup}}</b>{{end}} <i>{{.Subgroup}}</i>{{end}}
+{{else}}
+{{if .Emoji}}🕐 {{end}}<b>{{.Time}}</b>: <i>{{.Name}}</i>
+{{- if .Room}}; {{if .Emoji}}📍 {{end}}<i>{{.Room}}</i>{{end}}
+{{- if .Teacher}}; {{if .Emoji}}👤 {{end}}<i>{{.Teacher}}</i>{{end}}
+{{- if .Group}}; {{if .Emoji}}🎓 {{end}}<i>{{.Group}}</i>{{end}}
+{{- if .Subgroup}} ({{if .Emoji}}👥 {{end}}<i>{{.Subgroup}}</i>){{end}}
+{{- end}}{{end}}
+
+{{- define "day"}}{{.L.your_schedule}} ({{.Weekday}}, {{.Date}})
+{{range .Lessons}}{{template "lesson" .}}{{end}}{{end}}
+
+{{- define "week"}}{{range .}}
+<b>{{.Weekday}}</b> ({{.Date}}):
+{{range .Lessons}}{{template "lesson" .}}{{end}}
+{{end}}{{end}}`))
+
+var (
+	dayTemplate  = viewTemplates.Lookup("day")
+	weekTemplate = viewTemplates.Lookup("week")
+)
+
+var searchTemplate = template.Must(template.New("search").Parse(
+	`{{.L.search_title}} «{{.Query}}»:
+{{range .Lessons}}
+<b>{{.L.label_date}}</b> <i>{{.Date}}, {{.Weekday}}</i>
+<b>{{.L.label_time}}</b> <i>{{.Schedule.LessonTime}}</i>
+<b>{{.L.label_lesson}}</b> <i>{{.Schedule.LessonName}}</i>
+{{- if .Schedule.Location}}
+<b>{{.L.label_room}}</b> <i>{{.Schedule.Location}}</i>{{end}}
+{{- if .Schedule.Teacher}}
+<b>{{.L.label_teacher}}</b> <i>{{.Schedule.Teacher}}</i>{{end}}
+{{- if .Schedule.Subgroup}}
+<b>{{.L.label_subgroup}}</b> <i>{{.Schedule.Subgroup}}</i>{{end}}
+{{end}}`))
+
+type dayView struct {
+	Weekday string
+	Date    string
+	Lessons []lessonView
+	L       map[string]string
+}
+
+type lessonView struct {
+	Time     string
+	Name     string
+	Room     string
+	Teacher  string
+	Group    string
+	Subgroup string
+	Detailed bool
+	Emoji    bool
+	L        map[string]string
+}
+
+type lessonFormat struct {
+	detailed    bool
+	fullTeacher bool
+	showRooms   bool
+	emoji       bool
+	timeRange   bool
+	showGroups  bool
+	hideTeacher bool
+	lang        string
+}
+
+type searchView struct {
+	Query   string
+	Lessons []searchLessonView
+	L       map[string]string
+}
+
+type searchLessonView struct {
+	Date     string
+	Weekday  string
+	Schedule db.Schedule
+	L        map[string]string
+}
+
+func renderTemplate(lang string, t *template.Template, data interface{}) string {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		fmt.Printf("Failed to render %s template: %v\n", t.Name(), err)
+		return tr(lang, "render_failed")
+	}
+	return buf.String()
+}
+
+func escapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+func bold(s string) string {
+	return "<b>" + escapeHTML(s) + "</b>"
+}
+
+func groupHeader(lang, groupName string) string {
+	return bold(tr(lang, "group_header", groupName)) + "\n"
+}
+
+func dayFormat(prefs db.ViewPrefs, lang string) lessonFormat {
+	return viewFormat(prefs, lang, true)
+}
+
+func weekFormat(prefs db.ViewPrefs, lang string) lessonFormat {
+	return viewFormat(prefs, lang, false)
+}
+
+func viewFormat(prefs db.ViewPrefs, lang string, detailed bool) lessonFormat {
+	format := lessonFormat{
+		lang:        lang,
+		detailed:    detailed,
+		fullTeacher: detailed,
+		showRooms:   !prefs.HideRooms,
+		emoji:       prefs.Emoji,
+		timeRange:   detailed,
+	}
+
+	switch prefs.Layout {
+	case db.LayoutCompact:
+		format.detailed = false
+	case db.LayoutDetailed:
+		format.detailed = true
+	}
+	switch prefs.Teachers {
+	case db.TeachersFull:
+		format.fullTeacher = true
+	case db.TeachersShort:
+		format.fullTeacher = false
+	}
+	switch prefs.TimeFormat {
+	case db.TimeStart:
+		format.timeRange = false
+	case db.TimeRange:
+		format.timeRange = true
+	}
+
+	return format
+}
+
+func lessonViews(schedules []db.Schedule, format lessonFormat) []lessonView {
+	views := make([]lessonView, 0, len(schedules))
+	for _, schedule := range schedules {
+		view := lessonView{
+			Time:     schedule.LessonTime,
+			Name:     schedule.LessonName,
+			Teacher:  schedule.Teacher,
+			Subgroup: schedule.Subgroup,
+			Detailed: format.detailed,
+			Emoji:    format.emoji,
+			L:        catalog(format.lang),
+		}
+		if !format.timeRange {
+			view.Time = lessonStartTime(schedule)
+		}
+		if !format.fullTeacher {
+			view.Teacher = db.ShortTeacherName(schedule.Teacher)
+		}
+		if format.hideTeacher {
+			view.Teacher = ""
+		}
+		if format.showGroups {
+			view.Group = schedule.GroupName
+		}
+		if format.showRooms {
+			view.Room = schedule.Location
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func weekDays(schedules []db.Schedule, format lessonFormat) []dayView {
+	var days []dayView
+	start := 0
+	for i := range schedules {
+		if i+1 < len(schedules) && schedules[i+1].LessonDate == schedules[i].LessonDate {
+			continue
+		}
+		days = append(days, dayView{
+			Weekday: localDayOfWeek(format.lang, schedules[start].DayOfWeek),
+			Date:    schedules[start].LessonDate,
+			Lessons: lessonViews(schedules[start:i+1], format),
+			L:       catalog(format.lang),
+		})
+		start = i + 1
+	}
+	return days
+}
+
+func searchLessons(lessons []lessonOccurrence, lang string) []searchLessonView {
+	views := make([]searchLessonView, 0, len(lessons))
+	for _, lesson := range lessons {
+		views = append(views, searchLessonView{
+			Date:     dayMonth(lang, lesson.start),
+			Weekday:  localDayOfWeek(lang, lesson.schedule.DayOfWeek),
+			Schedule: lesson.schedule,
+			L:        catalog(lang),
+		})
+	}
+	return views
+}
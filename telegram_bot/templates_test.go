@@ -0,0 +1,181 @@
+package telegram_bot
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+)
+
+var (
+	telegramTagRe    = regexp.MustCompile(`^<(/?)(b|i|u|s|code|pre|a)(?: href="[^"<>]*")?>`)
+	telegramEntityRe = regexp.MustCompile(`^&(?:lt|gt|amp|quot|#\d+|#x[0-9a-fA-F]+);`)
+)
+
+// checkTelegramHTML fails the test unless text only uses the tags and
+// entities Telegram accepts in HTML parse mode, with every tag closed.
+func checkTelegramHTML(t *testing.T, text string) {
+	t.Helper()
+
+	var open []string
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			match := telegramTagRe.FindStringSubmatch(text[i:])
+			if match == nil {
+				t.Fatalf("unexpected tag at %d in %q", i, text)
+			}
+			if match[1] == "" {
+				open = append(open, match[2])
+			} else {
+				if len(open) == 0 || open[len(open)-1] != match[2] {
+					t.Fatalf("unbalanced </%s> at %d in %q", match[2], i, text)
+				}
+				open = open[:len(open)-1]
+			}
+			i += len(match[0])
+		case '&':
+			match := telegramEntityRe.FindString(text[i:])
+			if match == "" {
+				t.Fatalf("unescaped & at %d in %q", i, text)
+			}
+			i += len(match)
+		case '>':
+			t.Fatalf("unescaped > at %d in %q", i, text)
+		default:
+			i++
+		}
+	}
+	if len(open) > 0 {
+		t.Fatalf("unclosed tags %v in %q", open, text)
+	}
+}
+
+func hostileSchedules() []db.Schedule {
+	return []db.Schedule{
+		{
+			GroupName:  "22-ИТ-1 <b>",
+			LessonDate: "02.09",
+			DayOfWeek:  "Понедельник",
+			LessonTime: "08:30-09:50",
+			LessonName: `<script>alert("x")</script> & Co`,
+			Location:   "a_b*c`d` <i>",
+			Teacher:    "Иванов_Иван *Иванович* `x`",
+			Subgroup:   "1 & 2 > 3",
+		},
+		{
+			GroupName:  "22-ИТ-1 <b>",
+			LessonDate: "03.09",
+			DayOfWeek:  "Вторник",
+			LessonTime: "10:05-11:25",
+			LessonName: "Мат_анализ **bold** `code`",
+			Location:   "</i>&amp;",
+			Teacher:    "O'Brien <a href=\"x\">",
+		},
+	}
+}
+
+func TestScheduleViewsEscapeHostileText(t *testing.T) {
+	schedules := hostileSchedules()
+	day := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		prefs db.ViewPrefs
+	}{
+		{"default", db.ViewPrefs{}},
+		{"compact", db.ViewPrefs{Layout: db.LayoutCompact}},
+		{"detailed emoji", db.ViewPrefs{Layout: db.LayoutDetailed, Emoji: true, Teachers: db.TeachersFull}},
+		{"short teachers", db.ViewPrefs{Teachers: db.TeachersShort, TimeFormat: db.TimeStart}},
+	}
+
+	for _, tt := range tests {
+		for _, lang := range []string{"ru", "be", "en"} {
+			t.Run(tt.name+"/"+lang, func(t *testing.T) {
+				outputs := map[string]string{
+					"day":  formatSchedule(schedules[:1], day, tt.prefs, lang),
+					"week": formatWeeklySchedule(schedules, tt.prefs, lang),
+				}
+				for view, out := range outputs {
+					checkTelegramHTML(t, out)
+					if strings.Contains(out, "<script>") || strings.Contains(out, "<a href") {
+						t.Errorf("%s view leaks raw markup: %q", view, out)
+					}
+					plain := html.UnescapeString(out)
+					if !strings.Contains(plain, schedules[0].LessonName) {
+						t.Errorf("%s view lost lesson name: %q", view, out)
+					}
+				}
+
+				if tt.prefs.Layout == db.LayoutDetailed {
+					plain := html.UnescapeString(outputs["day"])
+					for _, s := range []string{schedules[0].Location, schedules[0].Teacher, schedules[0].Subgroup} {
+						if !strings.Contains(plain, s) {
+							t.Errorf("day view lost %q: %q", s, outputs["day"])
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestLessonViewsShowGroups(t *testing.T) {
+	schedules := hostileSchedules()
+	format := weekFormat(db.ViewPrefs{}, "ru")
+	format.showGroups = true
+
+	out := renderTemplate("ru", weekTemplate, weekDays(schedules, format))
+	checkTelegramHTML(t, out)
+	if !strings.Contains(out, escapeHTML(schedules[0].GroupName)) {
+		t.Errorf("group name is not escaped: %q", out)
+	}
+}
+
+func TestSearchTemplateEscapesHostileText(t *testing.T) {
+	var lessons []lessonOccurrence
+	for i, schedule := range hostileSchedules() {
+		lessons = append(lessons, lessonOccurrence{
+			schedule: schedule,
+			start:    time.Date(2024, 9, 2+i, 8, 30, 0, 0, time.UTC),
+		})
+	}
+
+	for _, query := range []string{"<b>", "a & b", "_*`"} {
+		out := renderTemplate("ru", searchTemplate, searchView{
+			Query:   query,
+			Lessons: searchLessons(lessons, "ru"),
+			L:       catalog("ru"),
+		})
+		checkTelegramHTML(t, out)
+
+		plain := html.UnescapeString(out)
+		for _, s := range []string{query, lessons[0].schedule.LessonName, lessons[1].schedule.Location, lessons[1].schedule.Teacher} {
+			if !strings.Contains(plain, s) {
+				t.Errorf("search view lost %q: %q", s, out)
+			}
+		}
+	}
+}
+
+func TestEscapeHTML(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"<b>", "&lt;b&gt;"},
+		{"a & b", "a &amp; b"},
+		{"&amp;", "&amp;amp;"},
+		{"_*`", "_*`"},
+	}
+	for _, tt := range tests {
+		if got := escapeHTML(tt.in); got != tt.want {
+			t.Errorf("escapeHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		checkTelegramHTML(t, bold(tt.in))
+	}
+}
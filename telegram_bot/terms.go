@@ -0,0 +1,105 @@
+package telegram_bot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+// termsVersion must be bumped whenever the "terms" text changes so that
+// every user is asked to accept the new version.
+const termsVersion = 1
+
+func handleTerms(bot *telebot.Bot, dbConn *pg.DB) {
+	personal := bot.Group()
+	personal.Use(privateOnly)
+
+	personal.Handle("/start", func(c telebot.Context) error {
+		reactivateUser(dbConn, senderUser(c))
+		if termsAccepted(c) {
+			return c.Send(t(c, "main_menu"), mainMenuButtons(langOf(c), roleOf(c)))
+		}
+		return sendTerms(c)
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "accept_terms"}, func(c telebot.Context) error {
+		if err := acceptTerms(dbConn, c.Sender().ID, time.Now()); err != nil {
+			return c.Edit(errText(c, "err_accept_terms", escapeHTML(err.Error())), termsOfServiceButtons(langOf(c)))
+		}
+		return c.Edit(t(c, "terms_accepted"), mainMenuButtons(langOf(c), roleOf(c)))
+	})
+
+	personal.Handle(&telebot.Btn{Unique: "decline_terms"}, func(c telebot.Context) error {
+		return c.Edit(t(c, "terms_declined"))
+	})
+}
+
+func termsOfServiceButtons(lang string) *telebot.ReplyMarkup {
+	return createMenu(2,
+		createButton(tr(lang, "btn_accept"), "accept_terms", ""),
+		createButton(tr(lang, "btn_decline"), "decline_terms", ""),
+	)
+}
+
+func termsAccepted(c telebot.Context) bool {
+	user := senderUser(c)
+	return user != nil && user.TermsVersion >= termsVersion
+}
+
+func sendTerms(c telebot.Context) error {
+	text := t(c, "terms")
+	if user := senderUser(c); user != nil && user.TermsVersion > 0 {
+		text = t(c, "terms_updated") + "\n\n" + text
+	}
+	return c.Send(text, termsOfServiceButtons(langOf(c)))
+}
+
+func requireTerms(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		if c.Sender() == nil || senderUser(c) == nil || isGroupChat(c.Chat()) || termsAccepted(c) || isTermsUpdate(c) {
+			return next(c)
+		}
+
+		if c.Query() != nil {
+			return c.Answer(&telebot.QueryResponse{
+				IsPersonal:        true,
+				SwitchPMText:      t(c, "inline_accept_terms"),
+				SwitchPMParameter: "terms",
+			})
+		}
+		if c.Callback() != nil {
+			if err := c.Respond(); err != nil {
+				return err
+			}
+		}
+		return sendTerms(c)
+	}
+}
+
+func isTermsUpdate(c telebot.Context) bool {
+	if callback := c.Callback(); callback != nil {
+		return callback.Unique == "accept_terms" || callback.Unique == "decline_terms"
+	}
+	if c.Message() != nil {
+		command, _, _ := strings.Cut(c.Text(), " ")
+		return command == "/start" || strings.HasPrefix(command, "/start@")
+	}
+	return false
+}
+
+func acceptTerms(dbConn *pg.DB, userID int64, now time.Time) error {
+	user := &db.Users{
+		TelegramID:      userID,
+		TermsAcceptedAt: now,
+		TermsVersion:    termsVersion,
+	}
+	_, err := dbConn.Model(user).
+		OnConflict("(telegram_id) DO UPDATE").
+		Set("terms_accepted_at = EXCLUDED.terms_accepted_at").
+		Set("terms_version = EXCLUDED.terms_version").
+		Insert()
+	return err
+}
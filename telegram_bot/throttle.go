@@ -0,0 +1,74 @@
+package telegram_bot
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	globalSendInterval  = time.Second / 30
+	privateSendInterval = time.Second
+	groupSendInterval   = 3 * time.Second
+	maxFloodRetries     = 3
+)
+
+type throttler struct {
+	mu         sync.Mutex
+	nextGlobal time.Time
+	nextChat   map[int64]time.Time
+}
+
+var sendLimiter = &throttler{nextChat: make(map[int64]time.Time)}
+
+func (t *throttler) wait(chatID int64) {
+	chatInterval := privateSendInterval
+	if chatID < 0 {
+		chatInterval = groupSendInterval
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	next := now
+	if t.nextGlobal.After(next) {
+		next = t.nextGlobal
+	}
+	if chatNext := t.nextChat[chatID]; chatNext.After(next) {
+		next = chatNext
+	}
+	t.nextGlobal = next.Add(globalSendInterval)
+	t.nextChat[chatID] = next.Add(chatInterval)
+
+	for id, chatNext := range t.nextChat {
+		if chatNext.Before(now) {
+			delete(t.nextChat, id)
+		}
+	}
+	t.mu.Unlock()
+
+	time.Sleep(time.Until(next))
+}
+
+func throttled[T any](chatID int64, send func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		sendLimiter.wait(chatID)
+
+		result, err := send()
+
+		var floodErr telebot.FloodError
+		if errors.As(err, &floodErr) && attempt < maxFloodRetries {
+			time.Sleep(time.Duration(floodErr.RetryAfter) * time.Second)
+			continue
+		}
+
+		return result, err
+	}
+}
+
+func sendThrottled(bot *telebot.Bot, chatID int64, what interface{}, opts ...interface{}) (*telebot.Message, error) {
+	return throttled(chatID, func() (*telebot.Message, error) {
+		return bot.Send(&telebot.Chat{ID: chatID}, what, opts...)
+	})
+}
@@ -0,0 +1,88 @@
+package telegram_bot
+
+import (
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+var (
+	layoutCycle     = []string{"", db.LayoutCompact, db.LayoutDetailed}
+	teachersCycle   = []string{"", db.TeachersFull, db.TeachersShort}
+	timeFormatCycle = []string{"", db.TimeStart, db.TimeRange}
+)
+
+var prefLabels = map[string]string{
+	"":                "pref_default",
+	db.LayoutCompact:  "pref_compact",
+	db.LayoutDetailed: "pref_detailed",
+	db.TeachersFull:   "pref_full",
+	db.TeachersShort:  "pref_short",
+	db.TimeStart:      "pref_start",
+	db.TimeRange:      "pref_range",
+}
+
+func handleViewPrefs(c telebot.Context, dbConn *pg.DB) error {
+	user, err := getUserInfo(dbConn, c.Sender().ID)
+	if err != nil {
+		return c.Edit(t(c, "choose_group_in_settings"), settingsMenuButtons(langOf(c)))
+	}
+
+	prefs := user.ViewPrefs
+	switch c.Data() {
+	case "":
+		return c.Edit(t(c, "view_prefs"), viewPrefsButtons(prefs, langOf(c)))
+	case "layout":
+		prefs.Layout = nextPref(layoutCycle, prefs.Layout)
+	case "teachers":
+		prefs.Teachers = nextPref(teachersCycle, prefs.Teachers)
+	case "time":
+		prefs.TimeFormat = nextPref(timeFormatCycle, prefs.TimeFormat)
+	case "rooms":
+		prefs.HideRooms = !prefs.HideRooms
+	case "emoji":
+		prefs.Emoji = !prefs.Emoji
+	case "reset":
+		prefs = db.ViewPrefs{}
+	}
+
+	_, err = dbConn.Model((*db.Users)(nil)).
+		Set("view_prefs = ?", prefs).
+		Where("telegram_id = ?", c.Sender().ID).
+		Update()
+	if err != nil {
+		return c.Edit(errText(c, "err_save_setting", escapeHTML(err.Error())), settingsMenuButtons(langOf(c)))
+	}
+
+	return c.Edit(t(c, "view_prefs"), viewPrefsButtons(prefs, langOf(c)))
+}
+
+func viewPrefsButtons(prefs db.ViewPrefs, lang string) *telebot.ReplyMarkup {
+	rooms := tr(lang, "pref_show")
+	if prefs.HideRooms {
+		rooms = tr(lang, "pref_hide")
+	}
+	emoji := tr(lang, "pref_off")
+	if prefs.Emoji {
+		emoji = tr(lang, "pref_on")
+	}
+
+	return createMenu(1,
+		createButton(tr(lang, "btn_pref_layout", tr(lang, prefLabels[prefs.Layout])), "view_prefs", "layout"),
+		createButton(tr(lang, "btn_pref_teachers", tr(lang, prefLabels[prefs.Teachers])), "view_prefs", "teachers"),
+		createButton(tr(lang, "btn_pref_rooms", rooms), "view_prefs", "rooms"),
+		createButton(tr(lang, "btn_pref_time", tr(lang, prefLabels[prefs.TimeFormat])), "view_prefs", "time"),
+		createButton(tr(lang, "btn_pref_emoji", emoji), "view_prefs", "emoji"),
+		createButton(tr(lang, "btn_reset"), "view_prefs", "reset"),
+		createButton(tr(lang, "btn_back"), "settings", ""),
+	)
+}
+
+func nextPref(cycle []string, current string) string {
+	for i, value := range cycle {
+		if value == current {
+			return cycle[(i+1)%len(cycle)]
+		}
+	}
+	return cycle[0]
+}
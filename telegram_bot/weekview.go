@@ -0,0 +1,87 @@
+package telegram_bot
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/Ah3ron/schedule-bot/db"
+	"github.com/Ah3ron/schedule-bot/render"
+	"github.com/go-pg/pg/v10"
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	weekViewText  = "text"
+	weekViewImage = "image"
+)
+
+func weekScheduleView(viewer scheduleViewer, schedules []db.Schedule, monday time.Time, page int) (interface{}, int, int) {
+	if len(schedules) == 0 {
+		return viewerHeader(viewer) + tr(viewer.lang, "no_week_schedule"), 0, 1
+	}
+
+	if viewer.weekImage && !viewer.isBanned {
+		title := tr(viewer.lang, "group_header", viewer.groupName) + " — " + tr(viewer.lang, "week_from", monday.Format("02.01"))
+		image, err := render.WeekImage(title, localWeekdays(viewer.lang, schedules), renderLabels(viewer.lang))
+		if err == nil {
+			return &telebot.Photo{
+				File:    telebot.FromReader(bytes.NewReader(image)),
+				Caption: escapeHTML(title),
+			}, 0, 1
+		}
+		fmt.Printf("Failed to render week image: %v\n", err)
+	}
+
+	pages := weekPages(viewerHeader(viewer), schedules, viewer.prefs, viewer.lang)
+	if page < 0 || page >= len(pages) {
+		page = 0
+	}
+	return viewer.obfuscate(pages[page]), page, len(pages)
+}
+
+func editOrReplace(c telebot.Context, what interface{}, opts ...interface{}) error {
+	message := c.Message()
+	if c.Callback() == nil || message == nil {
+		return c.Send(what, opts...)
+	}
+
+	_, sendingPhoto := what.(*telebot.Photo)
+	if sendingPhoto == (message.Photo != nil) {
+		return c.Edit(what, opts...)
+	}
+
+	if err := c.Delete(); err != nil {
+		fmt.Printf("Failed to delete message %d: %v\n", message.ID, err)
+	}
+	return c.Send(what, opts...)
+}
+
+func handleWeekViewSetting(c telebot.Context, dbConn *pg.DB) error {
+	view := c.Data()
+	if view == "" {
+		return c.Edit(t(c, "choose_week_view"), createMenu(1,
+			createButton(t(c, "btn_week_text"), "week_view", weekViewText),
+			createButton(t(c, "btn_week_image"), "week_view", weekViewImage),
+			createButton(t(c, "btn_back"), "settings", ""),
+		))
+	}
+
+	if view != weekViewImage {
+		view = weekViewText
+	}
+
+	_, err := dbConn.Model((*db.Users)(nil)).
+		Set("week_view = ?", view).
+		Where("telegram_id = ?", c.Sender().ID).
+		Update()
+	if err != nil {
+		return c.Edit(errText(c, "err_save_setting", escapeHTML(err.Error())), settingsMenuButtons(langOf(c)))
+	}
+
+	text := t(c, "week_view_text")
+	if view == weekViewImage {
+		text = t(c, "week_view_image")
+	}
+	return c.Edit(text, settingsMenuButtons(langOf(c)))
+}